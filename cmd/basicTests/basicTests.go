@@ -25,19 +25,23 @@ var (
 
 // These global variables are for dealing with command line options
 var (
-	sOptURL       = getopt.StringLong("url", 'u', "https://127.0.0.1:8000/", "TAXII Server Address", "string")
-	sOptProxy     = getopt.StringLong("proxy", 'x', "", "Proxy Server Address", "string")
-	sOptDiscovery = getopt.StringLong("discovery", 'd', "taxii2", "Name of Discovery Service", "string")
-	sOptAPIRoot   = getopt.StringLong("apiroot", 'a', "api1", "Name of API Root", "string")
-	sOptReadOnly  = getopt.StringLong("readonly", 'r', "22f763c1-e478-4765-8635-e4c32db665ea", "The read-only collection ID", "string")
-	sOptWriteOnly = getopt.StringLong("writeonly", 'w', "4f7327e2-f5b4-4269-b6e0-3564d174ce69", "The write-only collection ID", "string")
-	sOptReadWrite = getopt.StringLong("readwrite", 'z', "8c49f14d-8ea3-4f03-ab28-19dbca973dde", "The read-write collection ID", "string")
-	sOptUsername  = getopt.StringLong("username", 'n', "", "Username", "string")
-	sOptPassword  = getopt.StringLong("password", 'p', "", "Password", "string")
-	bOptVerbose   = getopt.BoolLong("verbose", 0, "Enable verbose output")
-	bOptDebug     = getopt.BoolLong("debug", 0, "Enable debug output")
-	bOptHelp      = getopt.BoolLong("help", 0, "Help")
-	bOptVer       = getopt.BoolLong("version", 0, "Version")
+	sOptURL               = getopt.StringLong("url", 'u', "https://127.0.0.1:8000/", "TAXII Server Address", "string")
+	sOptProxy             = getopt.StringLong("proxy", 'x', "", "Proxy Server Address", "string")
+	sOptDiscovery         = getopt.StringLong("discovery", 'd', "taxii2", "Name of Discovery Service", "string")
+	sOptAPIRoot           = getopt.StringLong("apiroot", 'a', "api1", "Name of API Root", "string")
+	sOptExpectedDiscovery = getopt.StringLong("discoveryfile", 'e', "", "File containing the expected discovery resource", "string")
+	sOptReadOnly          = getopt.StringLong("readonly", 'r', "22f763c1-e478-4765-8635-e4c32db665ea", "The read-only collection ID", "string")
+	sOptWriteOnly         = getopt.StringLong("writeonly", 'w', "4f7327e2-f5b4-4269-b6e0-3564d174ce69", "The write-only collection ID", "string")
+	sOptReadWrite         = getopt.StringLong("readwrite", 'z', "8c49f14d-8ea3-4f03-ab28-19dbca973dde", "The read-write collection ID", "string")
+	bOptFindCollections   = getopt.BoolLong("findcollections", 0, "Find the collection IDs from the collections endpoint")
+	sOptUsername          = getopt.StringLong("username", 'n', "", "Username", "string")
+	sOptPassword          = getopt.StringLong("password", 'p', "", "Password", "string")
+	sOptCredentials       = getopt.StringLong("credentials", 'c', "", "File containing the credentials for each API Root", "string")
+	bOptCrawl             = getopt.BoolLong("crawl", 0, "Test every API Root listed in the Discovery resource")
+	bOptVerbose           = getopt.BoolLong("verbose", 0, "Enable verbose output")
+	bOptDebug             = getopt.BoolLong("debug", 0, "Enable debug output")
+	bOptHelp              = getopt.BoolLong("help", 0, "Help")
+	bOptVer               = getopt.BoolLong("version", 0, "Version")
 )
 
 func main() {
@@ -54,9 +58,16 @@ func main() {
 	logger.Println("## ---------------------------------------------------------\n")
 
 	s.Setup()
+	if s.Settings.FindCollections {
+		s.FindCollectionIDs()
+	}
 	s.TestDiscoveryService()
-	s.TestAPIRootService()
-	s.TestCollectionsService()
+	if *bOptCrawl {
+		s.TestAllAPIRoots()
+	} else {
+		s.TestAPIRootService()
+		s.TestCollectionsService()
+	}
 }
 
 // --------------------------------------------------
@@ -98,12 +109,15 @@ func processCommandLineFlags(s *suite.Suite) {
 	s.Settings.Proxy = *sOptProxy
 	s.Settings.Discovery = *sOptDiscovery
 	s.Settings.APIRoot = *sOptAPIRoot
+	s.Settings.ExpectedDiscovery = *sOptExpectedDiscovery
 	s.Settings.Username = *sOptUsername
 	s.Settings.Password = *sOptPassword
+	s.Settings.Credentials = *sOptCredentials
 
 	s.CollectionIDs.ReadOnly = *sOptReadOnly
 	s.CollectionIDs.WriteOnly = *sOptWriteOnly
 	s.CollectionIDs.ReadWrite = *sOptReadWrite
+	s.Settings.FindCollections = *bOptFindCollections
 }
 
 /*
@@ -7,9 +7,11 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/freetaxii/libstix2/datastore/sqlite3"
 	"github.com/freetaxii/libstix2/objects/bundle"
@@ -33,6 +35,21 @@ var (
 	sOptDatabaseFilename    = getopt.StringLong("filename", 'f', defaultDatabaseFilename, "Database Filename", "string")
 	bOptIndicatorsOnly      = getopt.BoolLong("indicator", 'i', "Only print indicators")
 	bOptDatabase            = getopt.BoolLong("database", 0, "Add to database")
+	iOptGenerate            = getopt.IntLong("generate", 0, 0, "Generate a corpus with this many objects instead of the TestLab data", "int")
+	iOptSeed                = getopt.IntLong("seed", 0, 1, "The seed used to generate the corpus", "int")
+	iOptVersions            = getopt.IntLong("versions", 0, 3, "The maximum number of versions of each generated object", "int")
+	iOptSpread              = getopt.IntLong("spread", 0, 365, "The number of days the generated timestamps are spread over", "int")
+	sOptMix                 = getopt.StringLong("mix", 0, "", "The type mix of the generated corpus, like indicator=30,malware=10", "string")
+	sOptOutput              = getopt.StringLong("output", 'o', "corpus.json", "The file the generated corpus is written to", "string")
+	bOptTAXII               = getopt.BoolLong("taxii", 0, "Add to the read-only collection of a TAXII Server")
+	iOptBatch               = getopt.IntLong("batch", 0, 1, "The number of objects in each envelope posted to the TAXII Server", "int")
+	sOptURL                 = getopt.StringLong("url", 'u', "https://127.0.0.1:8000/", "TAXII Server Address", "string")
+	sOptProxy               = getopt.StringLong("proxy", 'x', "", "Proxy Server Address", "string")
+	sOptAPIRoot             = getopt.StringLong("apiroot", 'a', "api1", "Name of API Root", "string")
+	sOptReadOnly            = getopt.StringLong("readonly", 'r', "22f763c1-e478-4765-8635-e4c32db665ea", "The read-only collection ID", "string")
+	sOptUsername            = getopt.StringLong("username", 'n', "", "Username", "string")
+	sOptPassword            = getopt.StringLong("password", 'p', "", "Password", "string")
+	bOptVerbose             = getopt.BoolLong("verbose", 0, "Enable verbose output")
 	bOptHelp                = getopt.BoolLong("help", 0, "Help")
 	bOptVer                 = getopt.BoolLong("version", 0, "Version")
 )
@@ -58,6 +75,19 @@ func main() {
 		defer ds.Close()
 	}
 
+	var seeder *suite.Seeder
+	if *bOptTAXII {
+		s := suite.New(logger)
+		s.Verbose = *bOptVerbose
+		s.Settings.URL = *sOptURL
+		s.Settings.Proxy = *sOptProxy
+		s.Settings.APIRoot = *sOptAPIRoot
+		s.Settings.Username = *sOptUsername
+		s.Settings.Password = *sOptPassword
+		s.Setup()
+		seeder = s.NewSeeder(*sOptReadOnly, *iOptBatch)
+	}
+
 	// ----------------------------------------------------------------------
 	//
 	// Create TAXII Collections
@@ -107,7 +137,11 @@ func main() {
 	// Need 1 campaign
 	// Need 2 relationship objects
 	// Need 1 sighting
-	// Total 8 objects plus versions
+	// Need 1 identity
+	// Need 4 TLP marking definitions
+	// Need 1 extension definition and 1 malware with custom properties
+	// Need 5 cyber-observable objects
+	// Total 20 objects plus versions
 	//
 	// Tests Objects Endpoint
 	// Get all objects correctly
@@ -129,14 +163,24 @@ func main() {
 	//
 	// Get manifests
 
+	if *iOptGenerate > 0 {
+		generateCorpus(seeder)
+		finishSeeding(ds, seeder)
+		return
+	}
+
 	fmt.Println("\n\nStart STIX Object Output")
 	b := bundle.New()
 	b.SetID("bundle--e5214f9b-ae28-4692-9394-2fd2ed85d78a")
 
 	counter := make(map[string]int)
 	iData := suite.GenerateIndicatorData()
+	iData = append(iData, suite.GenerateSpecVersionData()...)
 	for _, v := range iData {
 		b.AddObject(v)
+		if seeder != nil {
+			seeder.Add(v)
+		}
 		counter[v.ID]++
 		if database {
 			err = ds.AddObject(&v)
@@ -155,30 +199,68 @@ func main() {
 		apData := suite.GenerateAttackPatternData()
 		for _, v := range apData {
 			b.AddObject(v)
-			if database {
-				ds.AddObject(v)
-				err = ds.AddToCollection(c1.ID, v.ID)
-				handleError(err)
+			if seeder != nil {
+				seeder.Add(v)
 			}
 		}
 
 		taData := suite.GenerateThreatActorData()
 		for _, v := range taData {
 			b.AddObject(v)
-			if database {
-				ds.AddObject(v)
-				err = ds.AddToCollection(c1.ID, v.ID)
-				handleError(err)
+			if seeder != nil {
+				seeder.Add(v)
 			}
 		}
 
 		cData := suite.GenerateCampaignData()
 		for _, v := range cData {
 			b.AddObject(v)
-			if database {
-				ds.AddObject(v)
-				err = ds.AddToCollection(c1.ID, v.ID)
-				handleError(err)
+			if seeder != nil {
+				seeder.Add(v)
+			}
+		}
+
+		idData := suite.GenerateIdentityData()
+		for _, v := range idData {
+			b.AddObject(v)
+			if seeder != nil {
+				seeder.Add(v)
+			}
+		}
+
+		mData := suite.GenerateMarkingDefinitionData()
+		for _, v := range mData {
+			b.AddObject(v)
+			if seeder != nil {
+				seeder.Add(v)
+			}
+		}
+
+		rData := suite.GenerateRelationshipData()
+		for _, v := range rData {
+			b.AddObject(v)
+			if seeder != nil {
+				seeder.Add(v)
+			}
+		}
+
+		sData := suite.GenerateSightingData()
+		for _, v := range sData {
+			b.AddObject(v)
+			if seeder != nil {
+				seeder.Add(v)
+			}
+		}
+
+		// The extension and observable data are decoded JSON so the custom
+		// properties are kept exactly as they are.
+		var customData []suite.STIXObject
+		customData = append(customData, suite.GenerateExtensionData()...)
+		customData = append(customData, suite.GenerateObservableData()...)
+		for _, v := range customData {
+			b.AddObject(v)
+			if seeder != nil {
+				seeder.Add(v)
 			}
 		}
 	}
@@ -186,6 +268,72 @@ func main() {
 	data, _ = json.MarshalIndent(b, "", "    ")
 	fmt.Println(string(data))
 
+	finishSeeding(ds, seeder)
+}
+
+/*
+finishSeeding - This function will finish seeding the TAXII Server if the taxii
+option was given and exit with an error if any problems were found.
+*/
+func finishSeeding(ds *sqlite3.Store, seeder *suite.Seeder) {
+	if seeder == nil || seeder.Close() == 0 {
+		return
+	}
+	if ds != nil {
+		ds.Close()
+	}
+	os.Exit(1)
+}
+
+/*
+generateCorpus - This function will generate a corpus using the command line
+options and write it to the output file as a STIX bundle as each object is
+made. If the taxii option was given, each object is also posted to the
+read-only collection.
+*/
+func generateCorpus(seeder *suite.Seeder) {
+	options := suite.DefaultCorpusOptions()
+	options.Seed = int64(*iOptSeed)
+	options.Objects = *iOptGenerate
+	options.MaxVersions = *iOptVersions
+	options.Spread = time.Duration(*iOptSpread) * 24 * time.Hour
+	if *sOptMix != "" {
+		mix, err := suite.ParseTypeMix(*sOptMix)
+		handleError(err)
+		options.TypeMix = mix
+	}
+
+	f, err := os.Create(*sOptOutput)
+	handleError(err)
+	defer f.Close()
+	w := bufio.NewWriter(f)
+
+	fmt.Fprintf(w, "{\n    \"type\": \"bundle\",\n    \"id\": \"bundle--e5214f9b-ae28-4692-9394-2fd2ed85d78a\",\n    \"objects\": [")
+
+	total := 0
+	err = suite.GenerateCorpus(options, func(o suite.STIXObject) error {
+		data, err := json.Marshal(o)
+		if err != nil {
+			return err
+		}
+		if total > 0 {
+			w.WriteString(",")
+		}
+		w.WriteString("\n        ")
+		w.Write(data)
+		total++
+
+		if seeder != nil {
+			seeder.Add(o)
+		}
+		return nil
+	})
+	handleError(err)
+
+	fmt.Fprintf(w, "\n    ]\n}\n")
+	handleError(w.Flush())
+
+	fmt.Println("\n\nWrote", total, "object versions generated with seed", options.Seed, "to", *sOptOutput)
 }
 
 func handleError(err error) {
@@ -220,6 +368,22 @@ func processCommandLineFlags() {
 		getopt.Usage()
 		os.Exit(0)
 	}
+
+	// The generated corpus has objects that the database can not store, so it
+	// can only be loaded in to a TAXII Server.
+	if *iOptGenerate > 0 && *bOptDatabase {
+		fmt.Println("ERROR: --database can not be used with --generate, use --taxii to load a generated corpus")
+		os.Exit(1)
+	}
+
+	// The database can not store the marking definitions, the extension
+	// definition, the malware object or the STIX Cyber-observable Objects, and
+	// the test suite expects all of them once any object that is not an
+	// indicator is in the read-only collection.
+	if *bOptDatabase && !*bOptIndicatorsOnly {
+		fmt.Println("ERROR: --database can only be used with -i, use --taxii to load the full TestLab data")
+		os.Exit(1)
+	}
 }
 
 // printOutputHeader - This function will print a header for all console output
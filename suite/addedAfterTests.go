@@ -0,0 +1,289 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"io/ioutil"
+	"net/url"
+	"time"
+)
+
+/*
+TestAddedAfterROCollection - This method will perform the added_after filtering
+tests against the objects and manifest endpoints of the Read-Only collection.
+The date added values are read from the manifest so these tests do not depend
+on when the data was loaded. It will also check the X-TAXII-Date-Added-First
+and X-TAXII-Date-Added-Last headers.
+*/
+func (s *Suite) TestAddedAfterROCollection() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Testing Added After Read-Only Collection")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	s.Logger.Println("## Start Added After Tests for RO Collections\n")
+	// Test added_after using the first date added on the manifest endpoint
+	// Test added_after using a time between two date added values on the manifest endpoint
+	// Test added_after using the last date added on the manifest endpoint
+	// Test added_after using the first date added on the objects endpoint
+	// Test added_after using a time between two date added values on the objects endpoint
+	// Test the X-TAXII-Date-Added headers on the objects endpoint
+	// Test the X-TAXII-Date-Added headers on the manifest endpoint
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/"
+
+	records, problems := s.getManifestRecords(path)
+	if problems != 0 || len(records) == 0 {
+		s.Logger.Println("-- ERROR: Unable to get the date added values from the manifest, skipping the added after tests")
+		s.ProblemsFound += problems
+		if len(records) == 0 {
+			s.ProblemsFound++
+		}
+		s.printTestSummary()
+		return
+	}
+	sortRecordsByDateAdded(records)
+
+	first := records[0].DateAdded
+	last := records[len(records)-1].DateAdded
+	between := timestampBetween(records)
+
+	s.setPath(path + "manifest/")
+	s.testAddedAfter01(records, first)
+	s.testAddedAfter02(records, between)
+	s.testAddedAfter03(records, last)
+
+	s.setPath(path + "objects/")
+	s.testAddedAfter04(records, first)
+	s.testAddedAfter05(records, between)
+	s.testDateAddedHeaders01(records)
+
+	s.setPath(path + "manifest/")
+	s.testDateAddedHeaders02(records)
+}
+
+/*
+testAddedAfter01 - This method will make sure that using the first date added
+value does not return the records that were added at that exact time.
+*/
+func (s *Suite) testAddedAfter01(records []manifestRecord, addedAfter string) {
+	s.Logger.Println("## Test AA-01: Test Manifest Added After Using First Date Added")
+	s.Logger.Infoln("++ This test will filter the manifest of the read-only collection using the first date added value", addedAfter)
+	s.testAddedAfterResponse(resourceManifest, records, addedAfter)
+}
+
+/*
+testAddedAfter02 - This method will make sure that using a time between two
+date added values only returns the records that were added after that time.
+*/
+func (s *Suite) testAddedAfter02(records []manifestRecord, addedAfter string) {
+	s.Logger.Println("## Test AA-02: Test Manifest Added After Using Time Between Two Date Added Values")
+	if addedAfter == "" {
+		s.Logger.Println("++ Skipping this test, all of the records in the manifest have the same date added value\n")
+		return
+	}
+	s.Logger.Infoln("++ This test will filter the manifest of the read-only collection using the time", addedAfter)
+	s.testAddedAfterResponse(resourceManifest, records, addedAfter)
+}
+
+/*
+testAddedAfter03 - This method will make sure that using the last date added
+value returns nothing.
+*/
+func (s *Suite) testAddedAfter03(records []manifestRecord, addedAfter string) {
+	s.Logger.Println("## Test AA-03: Test Manifest Added After Using Last Date Added")
+	s.Logger.Infoln("++ This test will filter the manifest of the read-only collection using the last date added value", addedAfter)
+	s.testAddedAfterResponse(resourceManifest, records, addedAfter)
+}
+
+/*
+testAddedAfter04 - This method will make sure that using the first date added
+value does not return the objects that were added at that exact time.
+*/
+func (s *Suite) testAddedAfter04(records []manifestRecord, addedAfter string) {
+	s.Logger.Println("## Test AA-04: Test Objects Added After Using First Date Added")
+	s.Logger.Infoln("++ This test will filter the objects in the read-only collection using the first date added value", addedAfter)
+	s.testAddedAfterResponse(resourceEnvelope, records, addedAfter)
+}
+
+/*
+testAddedAfter05 - This method will make sure that using a time between two
+date added values only returns the objects that were added after that time.
+*/
+func (s *Suite) testAddedAfter05(records []manifestRecord, addedAfter string) {
+	s.Logger.Println("## Test AA-05: Test Objects Added After Using Time Between Two Date Added Values")
+	if addedAfter == "" {
+		s.Logger.Println("++ Skipping this test, all of the records in the manifest have the same date added value\n")
+		return
+	}
+	s.Logger.Infoln("++ This test will filter the objects in the read-only collection using the time", addedAfter)
+	s.testAddedAfterResponse(resourceEnvelope, records, addedAfter)
+}
+
+/*
+testDateAddedHeaders01 - This method will make sure the date added headers on
+the objects endpoint match the first and last objects that were returned.
+*/
+func (s *Suite) testDateAddedHeaders01(records []manifestRecord) {
+	s.Logger.Println("## Test AA-06: Test Objects X-TAXII-Date-Added Headers")
+	s.Logger.Infoln("++ This test will check the X-TAXII-Date-Added-First and X-TAXII-Date-Added-Last headers from the objects endpoint")
+	s.testDateAddedHeadersResponse(resourceEnvelope, records)
+}
+
+/*
+testDateAddedHeaders02 - This method will make sure the date added headers on
+the manifest endpoint match the first and last records that were returned.
+*/
+func (s *Suite) testDateAddedHeaders02(records []manifestRecord) {
+	s.Logger.Println("## Test AA-07: Test Manifest X-TAXII-Date-Added Headers")
+	s.Logger.Infoln("++ This test will check the X-TAXII-Date-Added-First and X-TAXII-Date-Added-Last headers from the manifest endpoint")
+	s.testDateAddedHeadersResponse(resourceManifest, records)
+}
+
+/*
+testAddedAfterResponse - This method is used by the added after tests. It will
+request every version of every object added after the time provided and make
+sure only the records with a later date added value are returned, in date
+added order.
+*/
+func (s *Suite) testAddedAfterResponse(kind string, records []manifestRecord, addedAfter string) {
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	after, _ := time.Parse(time.RFC3339Nano, addedAfter)
+	var expected []string
+	for _, v := range records {
+		added, _ := time.Parse(time.RFC3339Nano, v.DateAdded)
+		if added.After(after) {
+			expected = append(expected, versionKey(v.ID, v.Version))
+		}
+	}
+
+	params := url.Values{}
+	params.Set("match[version]", "all")
+	params.Set("added_after", addedAfter)
+
+	actual, problems := s.walkPages(kind, params, 0)
+	actual = fillUnversionedKeys(actual, records)
+	s.ProblemsFound += problems
+	s.ProblemsFound += s.compareKeys(expected, actual)
+
+	s.Logger.Infoln("++ Number of records expected:", len(expected))
+	s.Logger.Infoln("++ Number of records returned:", len(actual))
+
+	s.printTestSummary()
+}
+
+/*
+testDateAddedHeadersResponse - This method is used by the date added header
+tests. It will request every version of every object and compare the
+X-TAXII-Date-Added-First and X-TAXII-Date-Added-Last headers to the date added
+values of the first and last records that were returned.
+*/
+func (s *Suite) testDateAddedHeadersResponse(kind string, records []manifestRecord) {
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	dateAdded := make(map[string]string)
+	for _, v := range records {
+		dateAdded[versionKey(v.ID, v.Version)] = v.DateAdded
+	}
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+
+	values := s.Req.URL.Query()
+	values.Set("match[version]", "all")
+	s.Req.URL.RawQuery = values.Encode()
+
+	resp := s.doRequest()
+	defer resp.Body.Close()
+	s.ProblemsFound += s.checkResponseCode(resp.StatusCode, 200)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	s.handleError(err)
+
+	page, err := decodePage(kind, body)
+	if err != nil {
+		s.Logger.Println("-- ERROR: Invalid", kind, "resource returned", err)
+		s.ProblemsFound++
+		s.printTestSummary()
+		return
+	}
+
+	if len(page.Keys) == 0 {
+		s.Logger.Println("-- ERROR: No records were returned so the headers can not be checked")
+		s.ProblemsFound++
+		s.printTestSummary()
+		return
+	}
+
+	headers := []struct {
+		name string
+		key  string
+	}{
+		{"X-TAXII-Date-Added-First", page.Keys[0]},
+		{"X-TAXII-Date-Added-Last", page.Keys[len(page.Keys)-1]},
+	}
+	for i, v := range fillUnversionedKeys([]string{headers[0].key, headers[1].key}, records) {
+		headers[i].key = v
+	}
+
+	for _, h := range headers {
+		actual := resp.Header.Get(h.name)
+		expected, found := dateAdded[h.key]
+
+		if actual == "" {
+			s.Logger.Println("-- ERROR: Missing", h.name, "header")
+			s.ProblemsFound++
+		} else if !found {
+			s.Logger.Println("-- ERROR: Record", h.key, "was returned but is not in the manifest")
+			s.ProblemsFound++
+		} else if !sameTimestamp(actual, expected) {
+			s.Logger.Println("-- ERROR: Expected", h.name, "header", expected, "for record", h.key, "Got", actual)
+			s.ProblemsFound++
+		} else {
+			s.Logger.Infoln("++", h.name, "header", actual, "matches record", h.key)
+		}
+	}
+
+	s.printTestSummary()
+}
+
+/*
+timestampBetween - This function will find two neighboring date added values,
+as close to the middle of the sorted records as possible, and return a time
+that falls between them. It will return an empty string if every record has the
+same date added value.
+*/
+func timestampBetween(records []manifestRecord) string {
+	var result time.Time
+	best := -1
+	middle := len(records) / 2
+
+	for i := 0; i+1 < len(records); i++ {
+		t1, _ := time.Parse(time.RFC3339Nano, records[i].DateAdded)
+		t2, _ := time.Parse(time.RFC3339Nano, records[i+1].DateAdded)
+
+		// The time is written with microsecond precision, so the two values
+		// need to be at least two microseconds apart.
+		if t2.Sub(t1) < 2*time.Microsecond {
+			continue
+		}
+
+		distance := i - middle
+		if distance < 0 {
+			distance = -distance
+		}
+		if best == -1 || distance < best {
+			best = distance
+			result = t1.Add(t2.Sub(t1) / 2).Truncate(time.Microsecond)
+		}
+	}
+
+	if best == -1 {
+		return ""
+	}
+	return result.UTC().Format("2006-01-02T15:04:05.000000Z")
+}
@@ -8,7 +8,7 @@ package suite
 
 import (
 	"encoding/json"
-	"io/ioutil"
+	"math"
 
 	"github.com/freetaxii/libstix2/resources/apiroot"
 )
@@ -26,35 +26,175 @@ func (s *Suite) TestAPIRootService() {
 	s.setPath(s.Settings.APIRoot)
 
 	s.basicEndpointTests()
-	s.getAPIRootOutput()
+	raw := s.getAPIRootOutput()
+	if raw == nil {
+		s.Logger.Println("++ Skipping the remaining API root tests, the API root resource could not be decoded\n")
+		return
+	}
+	s.testAPIRootTitle(raw)
+	versions := s.testAPIRootVersions(raw)
+	s.testAPIRootMaxContentLength(raw)
+	s.testAPIRootContentType(versions)
 }
 
-func (s *Suite) getAPIRootOutput() {
+/*
+getAPIRootOutput - This method will get the API root resource and decode it in
+to a map, so that the properties can be checked one at a time and a property
+with the wrong type does not stop the rest of them from being checked.
+*/
+func (s *Suite) getAPIRootOutput() map[string]interface{} {
 	s.Logger.Println("## Test A1: Test successful response from api root endpoint")
 	if s.Verbose {
 		s.Logger.Println("++ This test will check to see if a proper API root resource is returned")
 		s.Logger.Println("++ Calling Path:", s.Req.URL.Path)
 	}
 
-	s.startTest()
-	s.setAccept(s.FullMediaType)
-	s.enableAuth(s.Settings.Username, s.Settings.Password)
+	var raw map[string]interface{}
+	_, problems := s.getResource("API Root", &raw)
+	if problems == 0 && raw == nil {
+		s.Logger.Println("-- ERROR: Invalid API Root resource returned, it is not a JSON object")
+		problems++
+	}
+	if problems != 0 {
+		s.ProblemsFound += problems
+		s.printTestSummary()
+		return nil
+	}
 
-	var o apiroot.APIRoot
-	resp, err := s.Client.Do(s.Req)
-	s.handleError(err)
-	defer resp.Body.Close()
-	s.ProblemsFound += s.checkResponseCode(resp.StatusCode, 200)
+	var data []byte
+	data, _ = json.MarshalIndent(raw, "", "    ")
+	s.Logger.Println("++ API Root Resource Returned:\n", string(data))
 
-	body, err := ioutil.ReadAll(resp.Body)
-	s.handleError(err)
+	s.printTestSummary()
+	return raw
+}
 
-	jerr := json.Unmarshal(body, &o)
-	s.handleError(jerr)
+/*
+testAPIRootTitle - This method will make sure the API root resource has a title
+that is a string.
+*/
+func (s *Suite) testAPIRootTitle(raw map[string]interface{}) {
+	s.Logger.Println("## Test A2: Test API Root Resource Title")
+	s.Logger.Infoln("++ This test will check to see if the required title property is present")
 
-	var data []byte
-	data, _ = json.MarshalIndent(o, "", "    ")
-	s.Logger.Println("++ API Root Resource Returned:\n", string(data))
+	value, found := raw["title"]
+	title, isString := value.(string)
+	if !found || (isString && title == "") {
+		s.Logger.Println("-- ERROR: API Root resource is missing the required title property")
+		s.ProblemsFound++
+	} else if !isString {
+		s.Logger.Println("-- ERROR: API Root resource title is not a string. Got", value)
+		s.ProblemsFound++
+	}
+
+	s.printTestSummary()
+}
+
+/*
+testAPIRootVersions - This method will make sure the API root resource has a
+list of versions that contains the TAXII 2.1 media type. It will return the
+versions that are strings so the Content-Type can be checked against them.
+*/
+func (s *Suite) testAPIRootVersions(raw map[string]interface{}) []string {
+	s.Logger.Println("## Test A3: Test API Root Resource Versions")
+	s.Logger.Infoln("++ This test will check to see if", s.FullMediaType, "is listed in versions")
+
+	var versions []string
+	value, found := raw["versions"]
+	list, isList := value.([]interface{})
+	if !found {
+		s.Logger.Println("-- ERROR: API Root resource is missing the required versions property")
+		s.ProblemsFound++
+	} else if !isList {
+		s.Logger.Println("-- ERROR: API Root resource versions is not a list. Got", value)
+		s.ProblemsFound++
+	}
+
+	for _, v := range list {
+		version, isString := v.(string)
+		if !isString {
+			s.Logger.Println("-- ERROR: API Root resource versions contains a value that is not a string. Got", v)
+			s.ProblemsFound++
+			continue
+		}
+		versions = append(versions, version)
+	}
+
+	if isList && !containsMediaType(versions, s.FullMediaType) {
+		s.Logger.Println("-- ERROR: API Root resource versions", versions, "does not contain", s.FullMediaType)
+		s.ProblemsFound++
+	}
 
 	s.printTestSummary()
+	return versions
+}
+
+/*
+testAPIRootMaxContentLength - This method will make sure the max_content_length
+of the API root resource is a positive integer. The decoded map is used so that
+values that are not integers are caught instead of being decoded as zero.
+*/
+func (s *Suite) testAPIRootMaxContentLength(raw map[string]interface{}) {
+	s.Logger.Println("## Test A4: Test API Root Resource Max Content Length")
+	s.Logger.Infoln("++ This test will check to see if max_content_length is a positive integer")
+
+	value, found := raw["max_content_length"]
+	number, isNumber := value.(float64)
+	if !found {
+		s.Logger.Println("-- ERROR: API Root resource is missing the required max_content_length property")
+		s.ProblemsFound++
+	} else if !isNumber || number != math.Trunc(number) || number <= 0 {
+		s.Logger.Println("-- ERROR: API Root resource max_content_length is not a positive integer. Got", value)
+		s.ProblemsFound++
+	}
+
+	s.printTestSummary()
+}
+
+/*
+testAPIRootContentType - This method will request the API root resource with and
+without a version on the Accept media type and make sure the Content-Type that
+is returned is for a version the API root says it supports. When a version is
+requested it needs to be the version that is returned.
+*/
+func (s *Suite) testAPIRootContentType(versions []string) {
+	s.Logger.Println("## Test A5: Test API Root Content-Type Matches Negotiated Version")
+	s.Logger.Infoln("++ This test will check to see if the Content-Type returned is listed in versions")
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	for _, v := range []string{s.TAXIIMediaType, s.FullMediaType} {
+		s.startTest()
+		s.setAccept(v)
+		s.enableAuth(s.Settings.Username, s.Settings.Password)
+
+		resp := s.doRequest()
+		resp.Body.Close()
+		contentType := resp.Header.Get("Content-type")
+
+		if !containsMediaType(versions, contentType) {
+			s.Logger.Println("-- ERROR: Content-Type", contentType, "returned for Accept", v, "is not listed in versions", versions)
+			s.ProblemsFound++
+		}
+		if v == s.FullMediaType {
+			s.ProblemsFound += s.checkContentType(contentType, s.FullMediaType)
+		}
+	}
+
+	s.printTestSummary()
+}
+
+/*
+getAPIRootResource - This method will get the API Root resource from the API
+Root endpoint and return it. It will return an integer representing the number
+of problems found.
+*/
+func (s *Suite) getAPIRootResource() (*apiroot.APIRoot, int) {
+	s.setPath(s.Settings.APIRoot)
+	s.Logger.Infoln("++ Getting API Root resource from:", s.Req.URL.Path)
+
+	var o apiroot.APIRoot
+	if _, problems := s.getResource("API Root", &o); problems != 0 {
+		return nil, problems
+	}
+	return &o, 0
 }
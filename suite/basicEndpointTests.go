@@ -37,8 +37,7 @@ func (s *Suite) testBE01() {
 	s.startTest()
 	s.setAccept(s.FullMediaType)
 
-	resp, err := s.Client.Do(s.Req)
-	s.handleError(err)
+	resp := s.doRequest()
 	defer resp.Body.Close()
 	s.ProblemsFound += s.checkResponseCode(resp.StatusCode, 401, 404)
 	s.printTestSummary()
@@ -53,8 +52,7 @@ func (s *Suite) testBE02() {
 	s.setAccept(s.FullMediaType)
 	s.enableAuth(s.Settings.Username, "foo")
 
-	resp, err := s.Client.Do(s.Req)
-	s.handleError(err)
+	resp := s.doRequest()
 	defer resp.Body.Close()
 	s.ProblemsFound += s.checkResponseCode(resp.StatusCode, 401, 404)
 	s.printTestSummary()
@@ -69,8 +67,7 @@ func (s *Suite) testBE03() {
 	s.setAccept(s.FullMediaType)
 	s.enableAuth(s.Settings.Username, s.Settings.Password)
 
-	resp, err := s.Client.Do(s.Req)
-	s.handleError(err)
+	resp := s.doRequest()
 	defer resp.Body.Close()
 	s.ProblemsFound += s.checkResponseCode(resp.StatusCode, 200)
 
@@ -90,8 +87,7 @@ func (s *Suite) testBE04() {
 	orig := s.Req.URL.Path
 	s.Req.URL.Path = strings.TrimSuffix(s.Req.URL.Path, "/")
 
-	resp, err := s.Client.Do(s.Req)
-	s.handleError(err)
+	resp := s.doRequest()
 	defer resp.Body.Close()
 	s.ProblemsFound += s.checkResponseCode(resp.StatusCode, 404)
 
@@ -113,8 +109,7 @@ func (s *Suite) testBE05() {
 		s.setAccept(v)
 		s.enableAuth(s.Settings.Username, s.Settings.Password)
 
-		resp, err := s.Client.Do(s.Req)
-		s.handleError(err)
+		resp := s.doRequest()
 		defer resp.Body.Close()
 		s.ProblemsFound += s.checkResponseCode(resp.StatusCode, 406)
 	}
@@ -136,8 +131,7 @@ func (s *Suite) testBE06() {
 		s.setAccept(v)
 		s.enableAuth(s.Settings.Username, s.Settings.Password)
 
-		resp, err := s.Client.Do(s.Req)
-		s.handleError(err)
+		resp := s.doRequest()
 		defer resp.Body.Close()
 		s.ProblemsFound += s.checkResponseCode(resp.StatusCode, 200)
 	}
@@ -159,8 +153,7 @@ func (s *Suite) testBE07() {
 		s.setAccept(v)
 		s.enableAuth(s.Settings.Username, s.Settings.Password)
 
-		resp, err := s.Client.Do(s.Req)
-		s.handleError(err)
+		resp := s.doRequest()
 		defer resp.Body.Close()
 		s.ProblemsFound += s.checkContentType(resp.Header.Get("Content-type"), m2)
 	}
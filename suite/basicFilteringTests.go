@@ -7,12 +7,13 @@
 package suite
 
 import (
-	"github.com/freetaxii/libstix2/objects"
+	"strings"
+
 	"github.com/freetaxii/libstix2/objects/indicator"
 	"github.com/freetaxii/libstix2/resources/envelope"
 )
 
-func (s *Suite) basicIndicatorFilteringTestsObjectsRO() {
+func (s *Suite) basicIndicatorFilteringTestsObjectsRO(fullCorpus bool) {
 	s.Logger.Println("## Start Objects Filtering Tests for RO Collections\n")
 	// Test no filtering
 	// Test version filtering using ALL
@@ -24,18 +25,50 @@ func (s *Suite) basicIndicatorFilteringTestsObjectsRO() {
 	// Test id filtering using single ID
 	// Test id filtering using two IDs
 	// Test type filtering using single Type
+	// Test type filtering using a single type that is not indicator
+	// Test type filtering using two types
+	// Test type filtering using indicator and another type
+	// Test id filtering using IDs of different types
+	// Test version filtering using ALL and IDs of different types
+	// Test version filtering using FIRST and types that are not indicator
 	allIndicators := GenerateIndicatorData()
 
-	s.testFilter01([]indicator.Indicator{allIndicators[4], allIndicators[5]})
-	s.testFilter02([]indicator.Indicator{allIndicators[0], allIndicators[1], allIndicators[2], allIndicators[3], allIndicators[4], allIndicators[5]})
-	s.testFilter03([]indicator.Indicator{allIndicators[0], allIndicators[5]})
-	s.testFilter04([]indicator.Indicator{allIndicators[4], allIndicators[5]})
-	s.testFilter05([]indicator.Indicator{allIndicators[0], allIndicators[4], allIndicators[5]})
-	s.testFilter06([]indicator.Indicator{allIndicators[1]})
-	s.testFilter07([]indicator.Indicator{allIndicators[0], allIndicators[2], allIndicators[4], allIndicators[5]})
-	s.testFilter08([]indicator.Indicator{allIndicators[4]})
-	s.testFilter09([]indicator.Indicator{allIndicators[4], allIndicators[5]})
-	s.testFilter10([]indicator.Indicator{allIndicators[4], allIndicators[5]})
+	// The objects endpoint will also return the indicators from the spec
+	// version data. Only the STIX 2.1 version of indicator 3 is returned since
+	// the latest spec version is used when match[spec_version] is not given.
+	specIndicators := GenerateSpecVersionData()
+
+	// When the full corpus is loaded, the other objects are added after the
+	// indicators and only have one version, so they come back at the end of
+	// every result that is not filtered by ID, type, or a specific version.
+	var others []interface{}
+	if fullCorpus {
+		others = otherFixtureObjects()
+	}
+
+	s.testFilter01(append(indicatorObjects([]indicator.Indicator{allIndicators[4], allIndicators[5], specIndicators[1], specIndicators[2]}), others...))
+	s.testFilter02(append(indicatorObjects([]indicator.Indicator{allIndicators[0], allIndicators[1], allIndicators[2], allIndicators[3], allIndicators[4], allIndicators[5], specIndicators[1], specIndicators[2]}), others...))
+	s.testFilter03(append(indicatorObjects([]indicator.Indicator{allIndicators[0], allIndicators[5], specIndicators[1], specIndicators[2]}), others...))
+	s.testFilter04(append(indicatorObjects([]indicator.Indicator{allIndicators[4], allIndicators[5], specIndicators[1], specIndicators[2]}), others...))
+	s.testFilter05(append(indicatorObjects([]indicator.Indicator{allIndicators[0], allIndicators[4], allIndicators[5], specIndicators[1], specIndicators[2]}), others...))
+	s.testFilter06(indicatorObjects([]indicator.Indicator{allIndicators[1]}))
+	s.testFilter07(append(indicatorObjects([]indicator.Indicator{allIndicators[0], allIndicators[2], allIndicators[4], allIndicators[5], specIndicators[1], specIndicators[2]}), others...))
+	s.testFilter08(indicatorObjects([]indicator.Indicator{allIndicators[4]}))
+	s.testFilter09(indicatorObjects([]indicator.Indicator{allIndicators[4], allIndicators[5]}))
+	s.testFilter10(indicatorObjects([]indicator.Indicator{allIndicators[4], allIndicators[5], specIndicators[1], specIndicators[2]}))
+
+	if !fullCorpus {
+		s.Logger.Println("++ Skipping the filtering tests for other STIX types, only data/indicators.json is loaded in the read-only collection\n")
+		return
+	}
+
+	attackPattern, threatActor, campaign := others[0], others[1], others[2]
+	s.testFilter11([]interface{}{attackPattern})
+	s.testFilter12([]interface{}{attackPattern, campaign})
+	s.testFilter13(append(indicatorObjects([]indicator.Indicator{allIndicators[4], allIndicators[5], specIndicators[1], specIndicators[2]}), threatActor))
+	s.testFilter14(append(indicatorObjects([]indicator.Indicator{allIndicators[4]}), attackPattern, campaign))
+	s.testFilter15(append(indicatorObjects([]indicator.Indicator{allIndicators[0], allIndicators[1], allIndicators[2], allIndicators[3], allIndicators[4]}), threatActor))
+	s.testFilter16([]interface{}{attackPattern, threatActor, campaign})
 }
 
 func (s *Suite) basicIndicatorFilteringTestsObjectRO() {
@@ -49,34 +82,36 @@ func (s *Suite) basicIndicatorFilteringTestsObjectRO() {
 	// Test version filtering using LAST,FIRST,VERSION
 	allIndicators := GenerateIndicatorData()
 
-	s.testFilter01([]indicator.Indicator{allIndicators[4]})
-	s.testFilter02([]indicator.Indicator{allIndicators[0], allIndicators[1], allIndicators[2], allIndicators[3], allIndicators[4]})
-	s.testFilter03([]indicator.Indicator{allIndicators[0]})
-	s.testFilter04([]indicator.Indicator{allIndicators[4]})
-	s.testFilter05([]indicator.Indicator{allIndicators[0], allIndicators[4]})
-	s.testFilter06([]indicator.Indicator{allIndicators[1]})
-	s.testFilter07([]indicator.Indicator{allIndicators[0], allIndicators[2], allIndicators[4]})
+	s.testFilter01(indicatorObjects([]indicator.Indicator{allIndicators[4]}))
+	s.testFilter02(indicatorObjects([]indicator.Indicator{allIndicators[0], allIndicators[1], allIndicators[2], allIndicators[3], allIndicators[4]}))
+	s.testFilter03(indicatorObjects([]indicator.Indicator{allIndicators[0]}))
+	s.testFilter04(indicatorObjects([]indicator.Indicator{allIndicators[4]}))
+	s.testFilter05(indicatorObjects([]indicator.Indicator{allIndicators[0], allIndicators[4]}))
+	s.testFilter06(indicatorObjects([]indicator.Indicator{allIndicators[1]}))
+	s.testFilter07(indicatorObjects([]indicator.Indicator{allIndicators[0], allIndicators[2], allIndicators[4]}))
 }
 
 /*
 testFilter01 - This method will ensure the correct indicators are returned from
-the read-only collection. There should be two returned.
+the read-only collection. There should be four returned, plus the other objects
+when the full corpus is loaded.
 */
-func (s *Suite) testFilter01(indicators []indicator.Indicator) {
+func (s *Suite) testFilter01(objects []interface{}) {
 	s.Logger.Println("## Test Filter-01: Test No Filtering")
 	s.Logger.Infoln("++ This test will not apply any filters to the read-only collection")
 
 	s.startTest()
 	s.setAccept(s.FullMediaType)
 	s.enableAuth(s.Settings.Username, s.Settings.Password)
-	s.testFilteringResponse(indicators)
+	s.testFilteringResponse(objects)
 }
 
 /*
 testFilter02 - This method will ensure the correct indicators are returned from
-the read-only collection. There should be six returned.
+the read-only collection. There should be eight returned, plus the other
+objects when the full corpus is loaded.
 */
-func (s *Suite) testFilter02(indicators []indicator.Indicator) {
+func (s *Suite) testFilter02(objects []interface{}) {
 	s.Logger.Println("## Test Filter-02: Test Version Filtering Using All")
 	s.Logger.Infoln("++ This test will filter the read-only collection by versions using the all keyword")
 
@@ -87,14 +122,15 @@ func (s *Suite) testFilter02(indicators []indicator.Indicator) {
 	values := s.Req.URL.Query()
 	values.Set("match[version]", "all")
 	s.Req.URL.RawQuery = values.Encode()
-	s.testFilteringResponse(indicators)
+	s.testFilteringResponse(objects)
 }
 
 /*
 testFilter03 - This method will ensure the correct indicators are returned from
-the read-only collection. There should be two returned.
+the read-only collection. There should be four returned, plus the other objects
+when the full corpus is loaded.
 */
-func (s *Suite) testFilter03(indicators []indicator.Indicator) {
+func (s *Suite) testFilter03(objects []interface{}) {
 	s.Logger.Println("## Test Filter-03: Test Version Filtering Using First")
 	s.Logger.Infoln("++ This test will filter the read-only collection by versions using the first keyword")
 
@@ -105,14 +141,15 @@ func (s *Suite) testFilter03(indicators []indicator.Indicator) {
 	values := s.Req.URL.Query()
 	values.Set("match[version]", "first")
 	s.Req.URL.RawQuery = values.Encode()
-	s.testFilteringResponse(indicators)
+	s.testFilteringResponse(objects)
 }
 
 /*
 testFilter04 - This method will ensure the correct indicators are returned from
-the read-only collection. There should be two returned.
+the read-only collection. There should be four returned, plus the other objects
+when the full corpus is loaded.
 */
-func (s *Suite) testFilter04(indicators []indicator.Indicator) {
+func (s *Suite) testFilter04(objects []interface{}) {
 	s.Logger.Println("## Test Filter-04: Test Version Filtering Using Last")
 	s.Logger.Infoln("++ This test will filter the read-only collection by versions using the last keyword")
 
@@ -123,14 +160,15 @@ func (s *Suite) testFilter04(indicators []indicator.Indicator) {
 	values := s.Req.URL.Query()
 	values.Set("match[version]", "last")
 	s.Req.URL.RawQuery = values.Encode()
-	s.testFilteringResponse(indicators)
+	s.testFilteringResponse(objects)
 }
 
 /*
 testFilter05 - This method will ensure the correct indicators are returned from
-the read-only collection. There should be three returned.
+the read-only collection. There should be five returned, plus the other objects
+when the full corpus is loaded.
 */
-func (s *Suite) testFilter05(indicators []indicator.Indicator) {
+func (s *Suite) testFilter05(objects []interface{}) {
 	s.Logger.Println("## Test Filter-05: Test Version Filtering Using First,Last")
 	s.Logger.Infoln("++ This test will filter the read-only collection by versions using the first and last keywords")
 
@@ -141,14 +179,14 @@ func (s *Suite) testFilter05(indicators []indicator.Indicator) {
 	values := s.Req.URL.Query()
 	values.Set("match[version]", "first,last")
 	s.Req.URL.RawQuery = values.Encode()
-	s.testFilteringResponse(indicators)
+	s.testFilteringResponse(objects)
 }
 
 /*
 testFilter06 - This method will ensure the correct indicators are returned from
 the read-only collection. There should be one returned.
 */
-func (s *Suite) testFilter06(indicators []indicator.Indicator) {
+func (s *Suite) testFilter06(objects []interface{}) {
 	s.Logger.Println("## Test Filter-06: Test Version Filtering Using Specific Version")
 	s.Logger.Infoln("++ This test will filter the read-only collection by version using the version 2018-08-08T01:52:01.234Z")
 
@@ -159,14 +197,15 @@ func (s *Suite) testFilter06(indicators []indicator.Indicator) {
 	values := s.Req.URL.Query()
 	values.Set("match[version]", "2018-08-08T01:52:01.234Z")
 	s.Req.URL.RawQuery = values.Encode()
-	s.testFilteringResponse(indicators)
+	s.testFilteringResponse(objects)
 }
 
 /*
 testFilter07 - This method will ensure the correct indicators are returned from
-the read-only collection. There should be four returned.
+the read-only collection. There should be six returned, plus the other objects
+when the full corpus is loaded.
 */
-func (s *Suite) testFilter07(indicators []indicator.Indicator) {
+func (s *Suite) testFilter07(objects []interface{}) {
 	s.Logger.Println("## Test Filter-07: Test Version Filtering Using Last,First,Version")
 	s.Logger.Infoln("++ This test will filter the read-only collection by version using the last, first, and version")
 
@@ -177,14 +216,14 @@ func (s *Suite) testFilter07(indicators []indicator.Indicator) {
 	values := s.Req.URL.Query()
 	values.Set("match[version]", "last,first,2018-08-08T01:53:01.345Z")
 	s.Req.URL.RawQuery = values.Encode()
-	s.testFilteringResponse(indicators)
+	s.testFilteringResponse(objects)
 }
 
 /*
 testFilter08 - This method will ensure the correct indicators
-are returned from the read-only collection. There should be four returned.
+are returned from the read-only collection. There should be one returned.
 */
-func (s *Suite) testFilter08(indicators []indicator.Indicator) {
+func (s *Suite) testFilter08(objects []interface{}) {
 	s.Logger.Println("## Test Filter-08: Test ID Filtering Using One ID")
 	s.Logger.Infoln("++ This test will filter the read-only collection by ID using a single STIX ID")
 
@@ -195,14 +234,14 @@ func (s *Suite) testFilter08(indicators []indicator.Indicator) {
 	values := s.Req.URL.Query()
 	values.Set("match[id]", "indicator--1efc6673-9d95-46c3-a09c-c29f926da9af")
 	s.Req.URL.RawQuery = values.Encode()
-	s.testFilteringResponse(indicators)
+	s.testFilteringResponse(objects)
 }
 
 /*
 testFilter09 - This method will ensure the correct indicators
-are returned from the read-only collection. There should be four returned.
+are returned from the read-only collection. There should be two returned.
 */
-func (s *Suite) testFilter09(indicators []indicator.Indicator) {
+func (s *Suite) testFilter09(objects []interface{}) {
 	s.Logger.Println("## Test Filter-09: Test ID Filtering Using Two IDs")
 	s.Logger.Infoln("++ This test will filter the read-only collection by ID using two STIX IDs")
 
@@ -213,14 +252,15 @@ func (s *Suite) testFilter09(indicators []indicator.Indicator) {
 	values := s.Req.URL.Query()
 	values.Set("match[id]", "indicator--1efc6673-9d95-46c3-a09c-c29f926da9af,indicator--213dea46-8750-4b8b-b988-aae8f86a62d6")
 	s.Req.URL.RawQuery = values.Encode()
-	s.testFilteringResponse(indicators)
+	s.testFilteringResponse(objects)
 }
 
 /*
-testFilter10 - This method will ensure the correct indicators are
-returned from the read-only collection. There should be two returned.
+testFilter10 - This method will ensure only indicators are returned from the
+read-only collection, even when the full corpus is loaded. There should be four
+returned.
 */
-func (s *Suite) testFilter10(indicators []indicator.Indicator) {
+func (s *Suite) testFilter10(objects []interface{}) {
 	s.Logger.Println("## Test Filter-10: Test Type Filtering Using Indicator")
 	s.Logger.Infoln("++ This test will filter the read-only collection by type using Indicator")
 
@@ -231,20 +271,148 @@ func (s *Suite) testFilter10(indicators []indicator.Indicator) {
 	values := s.Req.URL.Query()
 	values.Set("match[type]", "indicator")
 	s.Req.URL.RawQuery = values.Encode()
-	s.testFilteringResponse(indicators)
+	s.testFilteringResponse(objects, "indicator")
+}
+
+/*
+testFilter11 - This method will ensure only the attack pattern is returned from
+the read-only collection when filtering by its type. There should be one
+returned.
+*/
+func (s *Suite) testFilter11(objects []interface{}) {
+	s.Logger.Println("## Test Filter-11: Test Type Filtering Using Attack Pattern")
+	s.Logger.Infoln("++ This test will filter the read-only collection by type using Attack Pattern")
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+
+	values := s.Req.URL.Query()
+	values.Set("match[type]", "attack-pattern")
+	s.Req.URL.RawQuery = values.Encode()
+	s.testFilteringResponse(objects, "attack-pattern")
+}
+
+/*
+testFilter12 - This method will ensure only the objects of the two types are
+returned from the read-only collection. There should be two returned.
+*/
+func (s *Suite) testFilter12(objects []interface{}) {
+	s.Logger.Println("## Test Filter-12: Test Type Filtering Using Two Types")
+	s.Logger.Infoln("++ This test will filter the read-only collection by type using Attack Pattern and Campaign")
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+
+	values := s.Req.URL.Query()
+	values.Set("match[type]", "attack-pattern,campaign")
+	s.Req.URL.RawQuery = values.Encode()
+	s.testFilteringResponse(objects, "attack-pattern", "campaign")
+}
+
+/*
+testFilter13 - This method will ensure the latest version of each indicator and
+the threat actor are returned from the read-only collection. There should be
+five returned.
+*/
+func (s *Suite) testFilter13(objects []interface{}) {
+	s.Logger.Println("## Test Filter-13: Test Type Filtering Using Indicator and Threat Actor")
+	s.Logger.Infoln("++ This test will filter the read-only collection by type using Indicator and Threat Actor")
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+
+	values := s.Req.URL.Query()
+	values.Set("match[type]", "indicator,threat-actor")
+	s.Req.URL.RawQuery = values.Encode()
+	s.testFilteringResponse(objects, "indicator", "threat-actor")
 }
 
 /*
-testFilteringResponse - This method is used by other tests that will test filtering and
-ensure that the correct objects are returned.
+testFilter14 - This method will ensure the correct objects are returned from
+the read-only collection when filtering by the IDs of objects of different
+types. There should be three returned.
 */
-func (s *Suite) testFilteringResponse(correctIndicators []indicator.Indicator) {
+func (s *Suite) testFilter14(objects []interface{}) {
+	s.Logger.Println("## Test Filter-14: Test ID Filtering Using IDs of Different Types")
+	s.Logger.Infoln("++ This test will filter the read-only collection by ID using an indicator, an attack pattern, and a campaign")
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+
+	ids := []string{
+		GenerateIndicatorData()[0].ID,
+		GenerateAttackPatternData()[0].ID,
+		GenerateCampaignData()[0].ID,
+	}
+
+	values := s.Req.URL.Query()
+	values.Set("match[id]", strings.Join(ids, ","))
+	s.Req.URL.RawQuery = values.Encode()
+	s.testFilteringResponse(objects)
+}
+
+/*
+testFilter15 - This method will ensure every version of an indicator and the
+threat actor are returned from the read-only collection. There should be six
+returned.
+*/
+func (s *Suite) testFilter15(objects []interface{}) {
+	s.Logger.Println("## Test Filter-15: Test Version Filtering Using All With IDs of Different Types")
+	s.Logger.Infoln("++ This test will filter the read-only collection by versions using the all keyword and the IDs of an indicator and a threat actor")
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+
+	ids := []string{
+		GenerateIndicatorData()[0].ID,
+		GenerateThreatActorData()[0].ID,
+	}
+
+	values := s.Req.URL.Query()
+	values.Set("match[id]", strings.Join(ids, ","))
+	values.Set("match[version]", "all")
+	s.Req.URL.RawQuery = values.Encode()
+	s.testFilteringResponse(objects)
+}
+
+/*
+testFilter16 - This method will ensure the first version of the attack pattern,
+threat actor, and campaign are returned from the read-only collection. There
+should be three returned.
+*/
+func (s *Suite) testFilter16(objects []interface{}) {
+	s.Logger.Println("## Test Filter-16: Test Version Filtering Using First With Types That Are Not Indicator")
+	s.Logger.Infoln("++ This test will filter the read-only collection by versions using the first keyword and the types Attack Pattern, Threat Actor, and Campaign")
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+
+	values := s.Req.URL.Query()
+	values.Set("match[type]", "attack-pattern,threat-actor,campaign")
+	values.Set("match[version]", "first")
+	s.Req.URL.RawQuery = values.Encode()
+	s.testFilteringResponse(objects, "attack-pattern", "threat-actor", "campaign")
+}
+
+/*
+testFilteringResponse - This method is used by other tests that will test
+filtering and ensure that the correct objects are returned. The objects are
+compared by ID and modified timestamp so any type of STIX object can be
+expected. If types are provided, every object that is returned must be one of
+those types.
+*/
+func (s *Suite) testFilteringResponse(expected []interface{}, types ...string) {
 	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
 	s.Logger.Infoln("++ Query Params:", s.makePrettyQueryParams())
 
 	// Make HTTP Request
-	resp, err := s.Client.Do(s.Req)
-	s.handleError(err)
+	resp := s.doRequest()
 	defer resp.Body.Close()
 
 	// Check HTTP response code first
@@ -258,54 +426,14 @@ func (s *Suite) testFilteringResponse(correctIndicators []indicator.Indicator) {
 		return
 	}
 
-	count := 0
-
-	if len(envelopeFromResponse.Objects) > 0 {
-		for index, v := range envelopeFromResponse.Objects {
-
-			// Make a first pass to decode just the object type value. Once we have this
-			// value we can easily make a second pass and decode the rest of the object.
-			stixtype, err := objects.DecodeType(v)
-			if err != nil {
-				// We should probably log the error here
-				continue
-			}
-
-			switch stixtype {
-			case "indicator":
-				o, _, err := indicator.Decode(v)
-				if err != nil {
-					// We should probably log the error here
-					continue
-				}
-
-				if valid, problems, details := correctIndicators[index].Compare(o); valid != true {
-					s.ProblemsFound += problems
-					if s.Debug {
-						for _, v := range details {
-							s.Logger.Debugln(v)
-						}
-					}
-					s.Logger.Println("-- ERROR: Returned indicator", o.ID, "version", o.Modified, "does not match expected")
-
-				} else {
-					if s.Debug {
-						for _, v := range details {
-							s.Logger.Debugln(v)
-						}
-					}
-					s.Logger.Infoln("++ Returned indicator", o.ID, "version", o.Modified, "matches expected")
-				}
-			}
-
-			count++
-		}
-		s.Logger.Infoln("++ Number objects returned:", count)
-
-		if s.Debug {
-			data, _ := envelopeFromResponse.EncodeToString()
-			s.Logger.Debugln("++ Envelope Resource Returned:\n", data)
-		}
+	s.ProblemsFound += s.compareObjects(expected, envelopeFromResponse.Objects)
+	if len(types) != 0 {
+		s.ProblemsFound += s.checkObjectTypes(envelopeFromResponse.Objects, types)
+	}
+
+	if s.Debug {
+		data, _ := envelopeFromResponse.EncodeToString()
+		s.Logger.Debugln("++ Envelope Resource Returned:\n", data)
 	}
 
 	s.printTestSummary()
@@ -32,8 +32,8 @@ func (s *Suite) TestROCollectionService() {
 	if s.Verbose {
 		s.Logger.Println("++ This test will check to see if a proper read-only collection resource is returned")
 	}
-	c := GenerateROCollection()
-	s.testCollectionResponse(c)
+	c, full := s.expectedCollection(s.CollectionIDs.ReadOnly, GenerateROCollection())
+	s.testCollectionResponse(c, full)
 }
 
 /*
@@ -43,6 +43,9 @@ output from the GET request is correct and will echo the output to the logs.
 */
 func (s *Suite) TestWOCollectionService() {
 	s.Logger.Println("## Testing Write-Only Collection Service")
+	if s.writeTestsNotApplicable() {
+		return
+	}
 
 	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.WriteOnly + "/"
 	s.setPath(path)
@@ -53,8 +56,8 @@ func (s *Suite) TestWOCollectionService() {
 	if s.Verbose {
 		s.Logger.Println("++ This test will check to see if a proper write-only collection resource is returned")
 	}
-	c := GenerateWOCollection()
-	s.testCollectionResponse(c)
+	c, full := s.expectedCollection(s.CollectionIDs.WriteOnly, GenerateWOCollection())
+	s.testCollectionResponse(c, full)
 }
 
 /*
@@ -64,6 +67,9 @@ output from the GET request is correct and will echo the output to the logs.
 */
 func (s *Suite) TestRWCollectionService() {
 	s.Logger.Println("## Testing Read-Write Collection Service")
+	if s.writeTestsNotApplicable() {
+		return
+	}
 
 	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadWrite + "/"
 	s.setPath(path)
@@ -74,15 +80,17 @@ func (s *Suite) TestRWCollectionService() {
 	if s.Verbose {
 		s.Logger.Println("++ This test will check to see if a proper read-write collection resource is returned")
 	}
-	c := GenerateRWCollection()
-	s.testCollectionResponse(c)
+	c, full := s.expectedCollection(s.CollectionIDs.ReadWrite, GenerateRWCollection())
+	s.testCollectionResponse(c, full)
 }
 
 /*
 testCollectionResponse - This method is used by other tests that will test
-to ensure that the correct objects are returned.
+to ensure that the correct objects are returned. When full is false only the
+id, title, can_read, can_write and media_types properties are compared, since
+the rest of the collection depends on the configuration of the TAXII Server.
 */
-func (s *Suite) testCollectionResponse(c *collections.Collection) {
+func (s *Suite) testCollectionResponse(c *collections.Collection, full bool) {
 	if s.Verbose {
 		s.Logger.Println("++ Calling Path:", s.Req.URL.Path)
 	}
@@ -91,8 +99,7 @@ func (s *Suite) testCollectionResponse(c *collections.Collection) {
 	s.setAccept(s.FullMediaType)
 	s.enableAuth(s.Settings.Username, s.Settings.Password)
 
-	resp, err := s.Client.Do(s.Req)
-	s.handleError(err)
+	resp := s.doRequest()
 	defer resp.Body.Close()
 	s.ProblemsFound += s.checkResponseCode(resp.StatusCode, 200)
 
@@ -103,7 +110,10 @@ func (s *Suite) testCollectionResponse(c *collections.Collection) {
 	jerr := json.Unmarshal(body, &o)
 	s.handleError(jerr)
 
-	if valid, problems, details := c.Compare(&o); valid != true {
+	if !full {
+		s.Logger.Infoln("++ Only the id, title, can_read, can_write and media_types properties of collection", c.ID, "are compared, use --collectionsfile to compare the whole collection")
+		s.ProblemsFound += s.compareCollectionProperties(c, &o)
+	} else if valid, problems, details := c.Compare(&o); valid != true {
 		s.ProblemsFound += problems
 		if s.Debug {
 			for _, v := range details {
@@ -131,3 +141,78 @@ func (s *Suite) testCollectionResponse(c *collections.Collection) {
 
 	s.printTestSummary()
 }
+
+/*
+compareCollectionProperties - This method will compare the id, can_read and
+can_write properties of the collection that was returned to the expected
+collection, along with the title and media_types when the expected collection
+has them. It will return an integer representing the number of problems found.
+*/
+func (s *Suite) compareCollectionProperties(c, o *collections.Collection) int {
+	problems := 0
+	if o.ID != c.ID {
+		s.Logger.Println("-- ERROR: Expected collection id", c.ID, "got", o.ID)
+		problems++
+	}
+	if c.Title != "" && o.Title != c.Title {
+		s.Logger.Println("-- ERROR: Expected collection", c.ID, "title to be", c.Title, "got", o.Title)
+		problems++
+	}
+	if o.CanRead != c.CanRead {
+		s.Logger.Println("-- ERROR: Expected collection", c.ID, "can_read to be", c.CanRead, "got", o.CanRead)
+		problems++
+	}
+	if o.CanWrite != c.CanWrite {
+		s.Logger.Println("-- ERROR: Expected collection", c.ID, "can_write to be", c.CanWrite, "got", o.CanWrite)
+		problems++
+	}
+	for _, v := range c.MediaTypes {
+		if !containsMediaType(o.MediaTypes, v) {
+			s.Logger.Println("-- ERROR: Expected collection", c.ID, "media_types to contain", v, "got", o.MediaTypes)
+			problems++
+		}
+	}
+	if problems != 0 {
+		s.Logger.Println("-- ERROR: Returned collection", c.ID, "does not match expected")
+	} else if s.Verbose {
+		s.Logger.Println("++ Returned collection", c.ID, "matches expected")
+	}
+	return problems
+}
+
+/*
+expectedCollection - This method will return the collection resource that is
+expected for the collection ID provided. If an expected collections file was
+provided and it has a collection with the same ID, that collection is used.
+Otherwise the default TestLab collection is used, and when the ID is not the
+default ID only the properties that do not depend on the configuration of the
+TAXII Server can be compared. It will return the collection and true if the
+whole collection can be compared.
+*/
+func (s *Suite) expectedCollection(id string, def *collections.Collection) (*collections.Collection, bool) {
+	if s.Settings.ExpectedCollections != "" {
+		var o struct {
+			Collections []collections.Collection `json:"collections"`
+		}
+
+		data, err := ioutil.ReadFile(s.Settings.ExpectedCollections)
+		if err == nil {
+			err = json.Unmarshal(data, &o)
+		}
+		if err != nil {
+			s.Logger.Println("-- ERROR: Unable to read the expected collections file, using the default collection", err)
+			s.ProblemsFound++
+		}
+
+		for i, c := range o.Collections {
+			if c.ID == id {
+				return &o.Collections[i], true
+			}
+		}
+	}
+
+	full := def.ID == id
+	expected := *def
+	expected.ID = id
+	return &expected, full
+}
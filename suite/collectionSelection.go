@@ -0,0 +1,91 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"github.com/freetaxii/libstix2/resources/collections"
+)
+
+/*
+collectionRole - This type describes how to find one of the three TestLab
+collections on the collections endpoint.
+*/
+type collectionRole struct {
+	name     string
+	alias    string
+	title    string
+	canRead  bool
+	canWrite bool
+	id       *string
+}
+
+/*
+FindCollectionIDs - This method will look at the collections endpoint and pick
+the read-only, write-only, and read-write collections to test. A collection is
+matched first by its alias, then by its title, and finally by its can_read and
+can_write flags. The IDs that are selected are logged. Any collection that can
+not be found will keep the ID it already had.
+*/
+func (s *Suite) FindCollectionIDs() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Finding TestLab Collections")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	o, problems := s.getCollectionsResource()
+	if problems != 0 || o == nil {
+		s.Logger.Println("-- ERROR: Unable to get the collections resource, using the configured collection IDs")
+		s.ProblemsFound += problems
+		s.printTestSummary()
+		return
+	}
+
+	roles := []collectionRole{
+		{"read-only", "testlab-read-only", GenerateROCollection().Title, true, false, &s.CollectionIDs.ReadOnly},
+		{"write-only", "testlab-write-only", GenerateWOCollection().Title, false, true, &s.CollectionIDs.WriteOnly},
+		{"read-write", "testlab-read-write", GenerateRWCollection().Title, true, true, &s.CollectionIDs.ReadWrite},
+	}
+
+	for _, r := range roles {
+		id, how := selectCollection(o, r)
+		if id == "" {
+			s.Logger.Println("-- ERROR: Unable to find a", r.name, "collection, using the configured ID", *r.id)
+			s.ProblemsFound++
+			continue
+		}
+		*r.id = id
+		s.Logger.Println("++ Selected", r.name, "collection", id, "by", how)
+	}
+
+	s.printTestSummary()
+}
+
+/*
+selectCollection - This function will find the collection that best matches the
+role provided. It will return the ID of the collection and how it was matched,
+or an empty string if nothing matched.
+*/
+func selectCollection(o *collections.Collections, r collectionRole) (string, string) {
+	for _, c := range o.Collections {
+		if c.Alias != "" && c.Alias == r.alias {
+			return c.ID, "alias"
+		}
+	}
+
+	for _, c := range o.Collections {
+		if c.Title == r.title {
+			return c.ID, "title"
+		}
+	}
+
+	for _, c := range o.Collections {
+		if c.CanRead == r.canRead && c.CanWrite == r.canWrite {
+			return c.ID, "can_read and can_write flags"
+		}
+	}
+
+	return "", ""
+}
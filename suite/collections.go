@@ -8,7 +8,6 @@ package suite
 
 import (
 	"encoding/json"
-	"io/ioutil"
 
 	"github.com/freetaxii/libstix2/resources/collections"
 )
@@ -37,21 +36,12 @@ func (s *Suite) getCollectionsOutput() {
 		s.Logger.Println("++ Calling Path:", s.Req.URL.Path)
 	}
 
-	s.startTest()
-	s.setAccept(s.FullMediaType)
-	s.enableAuth(s.Settings.Username, s.Settings.Password)
-
 	var o collections.Collections
-	resp, err := s.Client.Do(s.Req)
-	s.handleError(err)
-	defer resp.Body.Close()
-	s.ProblemsFound += s.checkResponseCode(resp.StatusCode, 200)
-
-	body, err := ioutil.ReadAll(resp.Body)
-	s.handleError(err)
-
-	jerr := json.Unmarshal(body, &o)
-	s.handleError(jerr)
+	if _, problems := s.getResource("Collections", &o); problems != 0 {
+		s.ProblemsFound += problems
+		s.printTestSummary()
+		return
+	}
 
 	var data []byte
 	data, _ = json.MarshalIndent(o, "", "    ")
@@ -59,3 +49,19 @@ func (s *Suite) getCollectionsOutput() {
 
 	s.printTestSummary()
 }
+
+/*
+getCollectionsResource - This method will get the Collections resource from the
+Collections endpoint and return it. It will return an integer representing the
+number of problems found.
+*/
+func (s *Suite) getCollectionsResource() (*collections.Collections, int) {
+	s.setPath(s.Settings.APIRoot + "collections/")
+	s.Logger.Infoln("++ Getting Collections resource from:", s.Req.URL.Path)
+
+	var o collections.Collections
+	if _, problems := s.getResource("Collections", &o); problems != 0 {
+		return nil, problems
+	}
+	return &o, 0
+}
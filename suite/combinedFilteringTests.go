@@ -0,0 +1,316 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+combinedFilter - This type holds a single filter that is used to build the
+combined filtering matrix.
+*/
+type combinedFilter struct {
+	name  string
+	param string
+	value string
+}
+
+/*
+combinedRecord - This type holds a single version of an object in the TestLab
+data along with the values that the combined filters are applied to.
+*/
+type combinedRecord struct {
+	key         string
+	id          string
+	objectType  string
+	specVersion string
+	versionTime time.Time
+	dateAdded   time.Time
+}
+
+/*
+TestCombinedFilteringROCollection - This method will perform the combined
+filtering tests against the objects endpoint of the Read-Only collection. Each
+test will combine two of match[id], match[type], match[version],
+match[spec_version], added_after, and limit and compare the result to the
+result of applying both filters to the TestLab data.
+*/
+func (s *Suite) TestCombinedFilteringROCollection() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Testing Combined Filtering Read-Only Collection")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	s.Logger.Println("## Start Combined Filtering Tests for RO Collections\n")
+	allIndicators := GenerateIndicatorData()
+	specIndicators := GenerateSpecVersionData()
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/"
+
+	objects := append(indicatorObjects(allIndicators), indicatorObjects(specIndicators)...)
+	if s.fullCorpusLoaded() {
+		objects = append(objects, otherFixtureObjects()...)
+	}
+
+	records, problems := s.getAllManifestRecords(path)
+	if problems != 0 {
+		s.Logger.Println("-- ERROR: Unable to get the manifest, skipping the combined filtering tests")
+		s.ProblemsFound += problems
+		s.printTestSummary()
+		return
+	}
+
+	data, missing := newCombinedRecords(objects, records)
+	if len(missing) != 0 {
+		for _, v := range missing {
+			s.Logger.Println("-- ERROR: Record", v, "is missing from the read-only collection")
+		}
+		s.Logger.Println("-- ERROR: The read-only collection does not contain the TestLab data, skipping the combined filtering tests")
+		s.ProblemsFound += len(missing)
+		s.printTestSummary()
+		return
+	}
+
+	sortRecordsByDateAdded(records)
+	between := timestampBetween(records)
+	if between == "" {
+		s.Logger.Println("-- ERROR: Unable to find a date added value to use with added_after, skipping the combined filtering tests")
+		s.ProblemsFound++
+		s.printTestSummary()
+		return
+	}
+
+	filters := []combinedFilter{
+		{"ID", "match[id]", allIndicators[0].ID + "," + specIndicators[0].ID},
+		{"Type", "match[type]", "indicator"},
+		{"Version", "match[version]", "first"},
+		{"Spec Version", "match[spec_version]", "2.0"},
+		{"Added After", "added_after", between},
+		{"Limit", "limit", "2"},
+	}
+
+	s.setPath(path + "objects/")
+	test := 1
+	for i := 0; i < len(filters); i++ {
+		for j := i + 1; j < len(filters); j++ {
+			s.testCombinedFilter(test, filters[i], filters[j], data, records)
+			test++
+		}
+	}
+}
+
+/*
+testCombinedFilter - This method will combine the two filters provided and
+compare the result to the versions of the TestLab data that both filters
+select. When one of the filters is limit, the result needs to be the first page
+of the other filter.
+*/
+func (s *Suite) testCombinedFilter(test int, f1, f2 combinedFilter, data []combinedRecord, records []manifestRecord) {
+	s.Logger.Printf("## Test CF-%02d: Test Combined Filtering Using %s and %s\n", test, f1.name, f2.name)
+	s.Logger.Infoln("++ This test will filter the read-only collection using", f1.param, "=", f1.value, "and", f2.param, "=", f2.value)
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	params := combinedFilterParams(f1, f2)
+	expected := combinedExpected(data, params)
+
+	if f2.param == "limit" {
+		s.testCombinedFilterLimit(params, expected, records)
+		return
+	}
+
+	actual, problems := s.walkPages(resourceEnvelope, params, 0)
+	s.ProblemsFound += problems
+	s.ProblemsFound += s.compareKeys(expected, fillUnversionedKeys(actual, records))
+
+	s.Logger.Infoln("++ Number of records expected:", len(expected))
+	s.Logger.Infoln("++ Number of records returned:", len(actual))
+
+	s.printTestSummary()
+}
+
+/*
+testCombinedFilterLimit - This method will make a single request using the
+parameters provided, which include a limit, and make sure the page that is
+returned is the start of the full result and that more is set correctly.
+*/
+func (s *Suite) testCombinedFilterLimit(params url.Values, full []string, records []manifestRecord) {
+	limit, _ := strconv.Atoi(params.Get("limit"))
+	expected := full
+	if len(expected) > limit {
+		expected = expected[:limit]
+	}
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+	s.Req.URL.RawQuery = params.Encode()
+	s.Logger.Infoln("++ Query Params:", s.makePrettyQueryParams())
+
+	resp := s.doRequest()
+	defer resp.Body.Close()
+	s.ProblemsFound += s.checkResponseCode(resp.StatusCode, 200)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	s.handleError(err)
+
+	page, err := decodePage(resourceEnvelope, body)
+	if err != nil {
+		s.Logger.Println("-- ERROR: Invalid envelope returned", err)
+		s.ProblemsFound++
+		s.printTestSummary()
+		return
+	}
+
+	s.ProblemsFound += s.compareKeys(expected, fillUnversionedKeys(page.Keys, records))
+
+	if more := len(full) > limit; page.More != more {
+		s.Logger.Println("-- ERROR: Expected more to be", more, "Got", page.More)
+		s.ProblemsFound++
+	}
+
+	s.printTestSummary()
+}
+
+/*
+combinedFilterParams - This function will build the URL parameters for the
+filters provided. Every version of every spec version is requested unless one
+of the filters says otherwise, so each filter is the only thing narrowing the
+result.
+*/
+func combinedFilterParams(filters ...combinedFilter) url.Values {
+	params := url.Values{}
+	params.Set("match[version]", "all")
+	params.Set("match[spec_version]", "2.0,2.1")
+	for _, f := range filters {
+		params.Set(f.param, f.value)
+	}
+	return params
+}
+
+/*
+newCombinedRecords - This function will make a record for every version of
+every object provided, using the date added value from the manifest record of
+the same version. The manifest uses the date added value as the version of
+objects that have neither a modified nor a created timestamp. The keys of the
+versions that are not in the manifest are returned so they can be reported.
+*/
+func newCombinedRecords(objects []interface{}, records []manifestRecord) ([]combinedRecord, []string) {
+	dateAdded := make(map[string]string)
+	for _, v := range records {
+		dateAdded[versionKey(v.ID, v.Version)] = v.DateAdded
+	}
+
+	keys := make([]string, 0, len(objects))
+	for _, v := range objects {
+		keys = append(keys, objectKey(v))
+	}
+	keys = fillUnversionedKeys(keys, records)
+
+	var data []combinedRecord
+	var missing []string
+	for i, v := range objects {
+		added, found := dateAdded[keys[i]]
+		if !found {
+			missing = append(missing, keys[i])
+			continue
+		}
+
+		var h stixObjectHeader
+		b, _ := json.Marshal(v)
+		json.Unmarshal(b, &h)
+		if h.SpecVersion == "" {
+			h.SpecVersion = "2.0"
+		}
+
+		r := combinedRecord{
+			key:         keys[i],
+			id:          h.ID,
+			objectType:  h.ObjectType,
+			specVersion: h.SpecVersion,
+		}
+		r.versionTime, _ = time.Parse(time.RFC3339Nano, strings.SplitN(keys[i], " version ", 2)[1])
+		r.dateAdded, _ = time.Parse(time.RFC3339Nano, added)
+		data = append(data, r)
+	}
+
+	sort.SliceStable(data, func(i, j int) bool {
+		return data[i].dateAdded.Before(data[j].dateAdded)
+	})
+	return data, missing
+}
+
+/*
+combinedExpected - This function will apply the URL parameters to the records
+and return the keys of the versions the server needs to return, in date added
+order. The ID, type, and spec version filters are applied first, then the
+first or last version is selected from the versions that are left, and last
+added_after removes the versions that were not added after the time given. The
+limit is not applied.
+*/
+func combinedExpected(data []combinedRecord, params url.Values) []string {
+	ids := paramSet(params.Get("match[id]"))
+	types := paramSet(params.Get("match[type]"))
+	specVersions := paramSet(params.Get("match[spec_version]"))
+	version := params.Get("match[version]")
+
+	var matched []combinedRecord
+	selected := make(map[string]combinedRecord)
+	for _, r := range data {
+		if len(ids) != 0 && !ids[r.id] {
+			continue
+		}
+		if len(types) != 0 && !types[r.objectType] {
+			continue
+		}
+		if !specVersions[r.specVersion] {
+			continue
+		}
+		matched = append(matched, r)
+
+		current, found := selected[r.id]
+		if !found || (version == "first" && r.versionTime.Before(current.versionTime)) || (version == "last" && r.versionTime.After(current.versionTime)) {
+			selected[r.id] = r
+		}
+	}
+
+	var addedAfter time.Time
+	if v := params.Get("added_after"); v != "" {
+		addedAfter, _ = time.Parse(time.RFC3339Nano, v)
+	}
+
+	var expected []string
+	for _, r := range matched {
+		if version != "all" && selected[r.id].key != r.key {
+			continue
+		}
+		if !addedAfter.IsZero() && !r.dateAdded.After(addedAfter) {
+			continue
+		}
+		expected = append(expected, r.key)
+	}
+	return expected
+}
+
+/*
+paramSet - This function will return a set of the values in a comma separated
+URL parameter. An empty parameter has no values.
+*/
+func paramSet(value string) map[string]bool {
+	set := make(map[string]bool)
+	if value == "" {
+		return set
+	}
+	for _, v := range strings.Split(value, ",") {
+		set[v] = true
+	}
+	return set
+}
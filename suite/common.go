@@ -6,8 +6,19 @@
 package suite
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/gologme/log"
 )
 
 // ----------------------------------------------------------------------
@@ -22,6 +33,10 @@ startTest - This method will prepare the environment for a test
 func (s *Suite) startTest() {
 	s.resetHeader()
 	s.Req.URL.RawQuery = ""
+	s.Req.Method = http.MethodGet
+	s.Req.Body = nil
+	s.Req.GetBody = nil
+	s.Req.ContentLength = 0
 }
 
 /*
@@ -46,6 +61,37 @@ func (s *Suite) setPath(p string) {
 	s.Req.URL.Path = p
 }
 
+/*
+setBody - This method will turn the current request in to a POST request with
+the body provided. The Content-Type header will only be set if a content type
+is provided.
+*/
+func (s *Suite) setBody(contentType string, data []byte) {
+	s.Req.Method = http.MethodPost
+	s.Req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	s.Req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+	s.Req.ContentLength = int64(len(data))
+	if contentType != "" {
+		s.Req.Header.Set("Content-Type", contentType)
+	}
+}
+
+/*
+normalizePath - This function will make sure a URL path starts and ends with a
+slash.
+*/
+func normalizePath(p string) string {
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	if !strings.HasSuffix(p, "/") {
+		p = p + "/"
+	}
+	return p
+}
+
 /*
 setAccept - This function will set the accept header to the string provided
 */
@@ -61,6 +107,56 @@ func (s *Suite) makePrettyQueryParams() string {
 	return pretty
 }
 
+/*
+doRequest - This method will send the current request and return the response.
+The body is read and put back so the caller can still decode it. Every error
+response is checked to make sure it contains a valid TAXII error message and
+any problems are added to the problems found for the current test, so the tests
+do not need to check it themselves.
+*/
+func (s *Suite) doRequest() *http.Response {
+	resp, err := s.Client.Do(s.Req)
+	s.handleError(err)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	s.handleError(err)
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if resp.StatusCode >= 400 {
+		s.ProblemsFound += s.checkErrorMessage(resp, body)
+	}
+	return resp
+}
+
+/*
+getResource - This method will get the resource at the current path and decode
+it in to the value provided. The name of the resource is used in the error
+messages. It will return the body and an integer representing the number of
+problems found.
+*/
+func (s *Suite) getResource(name string, o interface{}) ([]byte, int) {
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+
+	resp := s.doRequest()
+	defer resp.Body.Close()
+	if p := s.checkResponseCode(resp.StatusCode, 200); p != 0 {
+		return nil, p
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	s.handleError(err)
+
+	if err := json.Unmarshal(body, o); err != nil {
+		s.Logger.Println("-- ERROR: Invalid", name, "resource returned", err)
+		s.Logger.Debugln("++", name, "Resource Returned:\n", string(body))
+		return body, 1
+	}
+	return body, 0
+}
+
 /*
 checkResponseCode - This function will verify the actual HTTP response code
 against one more more possible expected response codes. It will return an integer
@@ -96,6 +192,70 @@ func (s *Suite) checkContentType(actual string, expected string) int {
 	return 0
 }
 
+/*
+checkErrorMessage - This function will verify that the body of an HTTP error
+response is a valid TAXII error message resource that was sent with the TAXII
+media type. It will return an integer representing the number of problems
+found.
+*/
+func (s *Suite) checkErrorMessage(resp *http.Response, body []byte) int {
+	problems := 0
+	s.Logger.Infoln("++ Checking error message returned with HTTP response code", resp.StatusCode)
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		s.Logger.Println("-- ERROR: Missing error message body on HTTP response code", resp.StatusCode)
+		return 1
+	}
+
+	problems += s.checkContentType(resp.Header.Get("Content-type"), s.FullMediaType)
+
+	var e errorMessage
+	if err := json.Unmarshal(body, &e); err != nil {
+		s.Logger.Println("-- ERROR: Malformed error message returned", err)
+		s.Logger.Debugln("++ Error Message Returned:\n", string(body))
+		return problems + 1
+	}
+
+	if e.Title == "" {
+		s.Logger.Println("-- ERROR: Error message is missing the required title property")
+		problems++
+	}
+
+	if e.HTTPStatus != "" && e.HTTPStatus != strconv.Itoa(resp.StatusCode) {
+		s.Logger.Println("-- ERROR: Error message http_status is", e.HTTPStatus, "but the HTTP response code is", resp.StatusCode)
+		problems++
+	}
+
+	if e.ExternalDetails != "" {
+		if u, err := url.Parse(e.ExternalDetails); err != nil || !u.IsAbs() {
+			s.Logger.Println("-- ERROR: Error message external_details is not a valid URL", e.ExternalDetails)
+			problems++
+		}
+	}
+
+	if problems == 0 {
+		s.Logger.Infoln("++ Valid error message returned:", e.Title)
+	}
+
+	return problems
+}
+
+/*
+containsMediaType - This function will check to see if a media type is in the
+list provided. Spaces around the parameters are ignored.
+*/
+func containsMediaType(list []string, mediaType string) bool {
+	normalize := func(m string) string {
+		return strings.ToLower(strings.Replace(m, " ", "", -1))
+	}
+	for _, v := range list {
+		if normalize(v) == normalize(mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
 /*
 handleError - This function will test the Go errors that come back from other
 function calls. This prevents us from having to put the if statement everywhere.
@@ -118,5 +278,116 @@ func (s *Suite) printTestSummary() {
 	} else if s.ProblemsFound > 1 {
 		s.Logger.Println("== FAILURE:", s.ProblemsFound, "problems found in this test\n")
 	}
+	s.problemsTotal += s.ProblemsFound
 	s.ProblemsFound = 0
 }
+
+/*
+compareKeys - This method will compare the keys of the records that were
+returned to the keys of the records that were expected. It will report
+duplicate, missing, and unexpected records along with records that are out of
+order. It will return an integer representing the number of problems found.
+*/
+func (s *Suite) compareKeys(expected, actual []string) int {
+	problems := 0
+
+	wanted := make(map[string]bool)
+	for _, v := range expected {
+		wanted[v] = true
+	}
+
+	seen := make(map[string]int)
+	for _, v := range actual {
+		seen[v]++
+		if seen[v] == 2 {
+			s.Logger.Println("-- ERROR: Record", v, "was returned more than once")
+			problems++
+		}
+		if !wanted[v] && seen[v] == 1 {
+			s.Logger.Println("-- ERROR: Record", v, "was returned but was not expected")
+			problems++
+		}
+	}
+
+	for _, v := range expected {
+		if seen[v] == 0 {
+			s.Logger.Println("-- ERROR: Record", v, "was expected but is missing")
+			problems++
+		}
+	}
+
+	// Only check the order if the same records came back, otherwise every
+	// record after the first gap would also be reported.
+	if problems == 0 && len(expected) == len(actual) {
+		for i := range expected {
+			if expected[i] != actual[i] {
+				s.Logger.Println("-- ERROR: Record", actual[i], "was returned at position", i+1, "but", expected[i], "was expected")
+				problems++
+				break
+			}
+		}
+	}
+
+	return problems
+}
+
+/*
+sameTimestamp - This function will compare two STIX or TAXII timestamps that
+may have been written with a different number of sub-second digits.
+*/
+func sameTimestamp(a, b string) bool {
+	ta, err := time.Parse(time.RFC3339Nano, a)
+	if err != nil {
+		return false
+	}
+	tb, err := time.Parse(time.RFC3339Nano, b)
+	if err != nil {
+		return false
+	}
+	return ta.Equal(tb)
+}
+
+/*
+newUUID - This function will return a random version 4 UUID that can be used to
+build STIX and TAXII identifiers.
+*/
+func newUUID() string {
+	u, err := readUUID(rand.Reader)
+	if err != nil {
+		log.Fatalln("-- FATAL: Unable to make a random UUID", err)
+	}
+	return u
+}
+
+/*
+readUUID - This function will read 16 bytes from the reader provided and
+return them as a version 4 UUID. It is used for random UUIDs and for the UUIDs
+of generated objects, which are read from a seeded random number generator.
+*/
+func readUUID(r io.Reader) (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return formatUUID(b, 4), nil
+}
+
+/*
+formatUUID - This function will set the version and variant bits of the first
+16 bytes provided and return them as a UUID string.
+*/
+func formatUUID(b []byte, version byte) string {
+	u := make([]byte, 16)
+	copy(u, b)
+	u[6] = (u[6] & 0x0f) | version<<4
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
+/*
+timestampNow - This function will return the current time as a STIX timestamp
+with millisecond precision.
+*/
+func timestampNow() string {
+	return time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+}
@@ -0,0 +1,249 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/freetaxii/libstix2/objects/indicator"
+)
+
+/*
+compareObjects - This method will compare the STIX objects that were returned
+to the STIX objects that were expected, for any type of STIX object. Each
+version of an object is identified by its ID and modified timestamp. Missing
+objects, unexpected objects, duplicate versions, and objects that are out of
+order are reported separately, and each object that was returned and expected
+has its properties compared. The expected objects are put in the date added
+order of the read-only collection first, since that is the order the server
+must return them in. It will return an integer representing the number of
+problems found.
+*/
+func (s *Suite) compareObjects(expected []interface{}, actual []json.RawMessage) int {
+	problems := 0
+	expected = s.sortByDateAdded(expected)
+
+	expectedKeys := make([]string, 0, len(expected))
+	expectedData := make(map[string][]byte)
+	for _, v := range expected {
+		data, err := json.Marshal(v)
+		s.handleError(err)
+
+		k := objectKey(v)
+		expectedKeys = append(expectedKeys, k)
+		expectedData[k] = data
+	}
+
+	actualKeys := make([]string, 0, len(actual))
+	actualData := make(map[string][]byte)
+	for i, v := range actual {
+		var h stixObjectHeader
+		if err := json.Unmarshal(v, &h); err != nil || h.ID == "" {
+			s.Logger.Println("-- ERROR: Object", i+1, "in the envelope could not be decoded as a STIX object", err)
+			problems++
+			continue
+		}
+
+		k := versionKey(h.ID, h.version())
+		actualKeys = append(actualKeys, k)
+		if _, found := actualData[k]; !found {
+			actualData[k] = v
+		}
+	}
+
+	s.Logger.Infoln("++ Number of objects expected:", len(expectedKeys))
+	s.Logger.Infoln("++ Number of objects returned:", len(actual))
+
+	problems += s.compareKeys(expectedKeys, actualKeys)
+
+	for _, k := range expectedKeys {
+		data, found := actualData[k]
+		if !found {
+			continue
+		}
+
+		if diffs := compareProperties(expectedData[k], data); len(diffs) != 0 {
+			s.Logger.Println("-- ERROR: Returned object", k, "does not match expected")
+			for _, v := range diffs {
+				s.Logger.Debugln("++ Property", v, "is different")
+			}
+			problems++
+		} else {
+			s.Logger.Infoln("++ Returned object", k, "matches expected")
+		}
+	}
+
+	return problems
+}
+
+/*
+sortByDateAdded - This method will return a copy of the objects provided sorted
+in ascending date added order. The date added value of each version is taken
+from the manifest of the read-only collection, which is only read the first
+time. Objects that are not in the manifest are left at the end in the order
+they were given.
+*/
+func (s *Suite) sortByDateAdded(objects []interface{}) []interface{} {
+	if s.dateAdded == nil {
+		s.dateAdded = s.getDateAdded()
+	}
+
+	type entry struct {
+		object interface{}
+		added  time.Time
+		found  bool
+	}
+
+	entries := make([]entry, 0, len(objects))
+	for _, v := range objects {
+		added, found := s.dateAdded[objectKey(v)]
+		entries = append(entries, entry{v, added, found})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].found != entries[j].found {
+			return entries[i].found
+		}
+		return entries[i].added.Before(entries[j].added)
+	})
+
+	sorted := make([]interface{}, 0, len(entries))
+	for _, v := range entries {
+		sorted = append(sorted, v.object)
+	}
+	return sorted
+}
+
+/*
+getDateAdded - This method will read the manifest of the read-only collection
+and return the date added value of every version of every object in it, keyed
+by objectKey. Objects without a modified or created timestamp use the date added
+value as their version in the manifest, so they are also listed without a
+version. The current request path and query are put back afterwards.
+*/
+func (s *Suite) getDateAdded() map[string]time.Time {
+	origPath := s.Req.URL.Path
+	origQuery := s.Req.URL.RawQuery
+
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/"
+	records, problems := s.getAllManifestRecords(path)
+	if problems != 0 {
+		s.Logger.Println("-- ERROR: Unable to get the date added values from the manifest of the read-only collection")
+		s.ProblemsFound += problems
+	}
+
+	s.Req.URL.Path = origPath
+	s.Req.URL.RawQuery = origQuery
+
+	added := make(map[string]time.Time)
+	for _, v := range records {
+		t, err := time.Parse(time.RFC3339Nano, v.DateAdded)
+		if err != nil {
+			continue
+		}
+		added[versionKey(v.ID, v.Version)] = t
+		if sameTimestamp(v.Version, v.DateAdded) {
+			added[versionKey(v.ID, "")] = t
+		}
+	}
+	return added
+}
+
+/*
+checkObjectTypes - This method will make sure every object that was returned is
+one of the types provided. It will return an integer representing the number of
+problems found.
+*/
+func (s *Suite) checkObjectTypes(actual []json.RawMessage, types []string) int {
+	problems := 0
+
+	allowed := make(map[string]bool)
+	for _, v := range types {
+		allowed[v] = true
+	}
+
+	for _, v := range actual {
+		var h stixObjectHeader
+		if err := json.Unmarshal(v, &h); err != nil {
+			// Objects that can not be decoded are reported by compareObjects
+			continue
+		}
+		if !allowed[h.ObjectType] {
+			s.Logger.Println("-- ERROR: Object", h.ID, "of type", h.ObjectType, "was returned but only", strings.Join(types, ","), "were requested")
+			problems++
+		}
+	}
+
+	return problems
+}
+
+/*
+compareProperties - This function will compare the JSON encoding of two STIX
+objects and return the names of the top level properties that are different.
+Timestamps that only differ in the number of sub-second digits are the same.
+*/
+func compareProperties(expected, actual []byte) []string {
+	var e, a map[string]interface{}
+	if err := json.Unmarshal(expected, &e); err != nil {
+		return []string{"(expected object could not be decoded)"}
+	}
+	if err := json.Unmarshal(actual, &a); err != nil {
+		return []string{"(returned object could not be decoded)"}
+	}
+
+	names := make(map[string]bool)
+	for k := range e {
+		names[k] = true
+	}
+	for k := range a {
+		names[k] = true
+	}
+
+	var diffs []string
+	for k := range names {
+		if reflect.DeepEqual(e[k], a[k]) {
+			continue
+		}
+		es, eok := e[k].(string)
+		as, aok := a[k].(string)
+		if eok && aok && sameTimestamp(es, as) {
+			continue
+		}
+		diffs = append(diffs, k)
+	}
+	sort.Strings(diffs)
+	return diffs
+}
+
+/*
+objectKey - This function will return the key that identifies the version of
+the STIX object provided, for any type of STIX object.
+*/
+func objectKey(o interface{}) string {
+	var h stixObjectHeader
+	data, err := json.Marshal(o)
+	if err == nil {
+		json.Unmarshal(data, &h)
+	}
+	return versionKey(h.ID, h.version())
+}
+
+/*
+indicatorObjects - This function will convert a list of indicators to a list of
+STIX objects that can be compared with compareObjects.
+*/
+func indicatorObjects(indicators []indicator.Indicator) []interface{} {
+	objects := make([]interface{}, 0, len(indicators))
+	for _, v := range indicators {
+		objects = append(objects, v)
+	}
+	return objects
+}
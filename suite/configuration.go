@@ -0,0 +1,95 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+// These constants identify the two ways the TAXII Server can be configured for
+// testing, as described in the README. ConfigurationUnknown means the
+// configuration has not been detected and every test will be run.
+const (
+	ConfigurationUnknown   = 0
+	ConfigurationReadOnly  = 1
+	ConfigurationReadWrite = 2
+)
+
+/*
+DetectConfiguration - This method will look at the collections endpoint and
+decide if the TAXII Server is set up as Configuration 1, a read-only
+implementation, or Configuration 2, a read and write implementation. The
+selected conformance profile is logged and the write tests are marked as not
+applicable when the TAXII Server is a read-only implementation.
+*/
+func (s *Suite) DetectConfiguration() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Detecting TAXII Server Configuration")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	s.Configuration = ConfigurationUnknown
+
+	o, problems := s.getCollectionsResource()
+	if problems != 0 || o == nil {
+		s.Logger.Println("-- ERROR: Unable to get the collections resource, every test will be run")
+		s.ProblemsFound += problems
+		s.printTestSummary()
+		return
+	}
+
+	var readOnly, writeOnly, readWrite bool
+	for _, c := range o.Collections {
+		switch c.ID {
+		case s.CollectionIDs.ReadOnly:
+			readOnly = c.CanRead
+		case s.CollectionIDs.WriteOnly:
+			writeOnly = c.CanWrite
+		case s.CollectionIDs.ReadWrite:
+			readWrite = c.CanRead && c.CanWrite
+		}
+	}
+
+	if writeOnly || readWrite {
+		s.Configuration = ConfigurationReadWrite
+	} else if readOnly {
+		s.Configuration = ConfigurationReadOnly
+	} else {
+		s.Logger.Println("-- ERROR: Unable to find the read-only collection", s.CollectionIDs.ReadOnly, "every test will be run")
+		s.ProblemsFound++
+		s.printTestSummary()
+		return
+	}
+
+	s.Logger.Println("++ Conformance profile:", s.configurationName())
+	if s.Configuration == ConfigurationReadOnly {
+		s.Logger.Println("++ No writable collections were found, the write tests are not applicable")
+	}
+	s.printTestSummary()
+}
+
+/*
+configurationName - This method will return the name of the configuration that
+was detected, as it is written in the README.
+*/
+func (s *Suite) configurationName() string {
+	switch s.Configuration {
+	case ConfigurationReadOnly:
+		return "Configuration 1: Read Only Implementation"
+	case ConfigurationReadWrite:
+		return "Configuration 2: Read and Write Implementation"
+	}
+	return "Unknown"
+}
+
+/*
+writeTestsNotApplicable - This method will check to see if the write tests can
+be run. If the TAXII Server is a read-only implementation it will log that the
+tests are not applicable and return true.
+*/
+func (s *Suite) writeTestsNotApplicable() bool {
+	if s.Configuration != ConfigurationReadOnly {
+		return false
+	}
+	s.Logger.Println("== NOT APPLICABLE: These tests require Configuration 2: Read and Write Implementation\n")
+	return true
+}
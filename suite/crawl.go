@@ -0,0 +1,147 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+)
+
+/*
+apiRootCredentials - This type holds the username and password to use for a
+single API root when crawling every API root
+*/
+type apiRootCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+/*
+TestAllAPIRoots - This method will read the api_roots from the Discovery
+resource and run the API Root and Collections tests against each one. If a
+credentials file was provided, the username and password for each API root are
+taken from it. The results are reported for each API root.
+*/
+func (s *Suite) TestAllAPIRoots() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Testing All API Roots From Discovery")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	credentials, problems := s.loadCredentials()
+	o, p := s.getDiscoveryResource()
+	problems += p
+	if problems != 0 || o == nil {
+		s.Logger.Println("-- ERROR: Unable to get the list of API roots, skipping the API root tests")
+		s.ProblemsFound += problems
+		s.printTestSummary()
+		return
+	}
+
+	// Save the settings so they can be put back after each API root
+	origAPIRoot := s.Settings.APIRoot
+	origUsername := s.Settings.Username
+	origPassword := s.Settings.Password
+	base := *s.Req.URL
+	origReqHost := s.Req.Host
+
+	var roots []string
+	results := make(map[string]int)
+
+	for _, v := range o.APIRoots {
+		u, err := url.Parse(v)
+		if err != nil {
+			s.Logger.Println("-- ERROR: Unable to parse API root", v, err)
+			continue
+		}
+		ref := base.ResolveReference(u)
+		root := normalizePath(ref.Path)
+		name := root
+
+		s.Settings.APIRoot = root
+		s.Settings.Username = origUsername
+		s.Settings.Password = origPassword
+		if ref.Scheme != base.Scheme || ref.Host != base.Host {
+			// The username and password given with -n and -p are for the
+			// TAXII Server given with -u, so an API root on another server
+			// only gets the credentials listed for its full URL.
+			name = ref.Scheme + "://" + ref.Host + root
+			s.Settings.Username = ""
+			s.Settings.Password = ""
+			s.Logger.Println("++ API root", v, "is on", ref.Scheme+"://"+ref.Host, "so it will be tested there")
+		}
+		if c, found := credentials[name]; found {
+			s.Settings.Username = c.Username
+			s.Settings.Password = c.Password
+		}
+		s.Req.URL.Scheme = ref.Scheme
+		s.Req.URL.Host = ref.Host
+		s.Req.Host = ref.Host
+		roots = append(roots, name)
+
+		s.Logger.Println("## ---------------------------------------------------------")
+		s.Logger.Println("## Testing API Root", name)
+		s.Logger.Println("## ---------------------------------------------------------\n")
+
+		before := s.problemsTotal
+		s.TestAPIRootService()
+		s.TestCollectionsService()
+		results[name] = s.problemsTotal - before
+	}
+
+	s.Req.URL.Scheme = base.Scheme
+	s.Req.URL.Host = base.Host
+	s.Req.Host = origReqHost
+	s.Settings.APIRoot = origAPIRoot
+	s.Settings.Username = origUsername
+	s.Settings.Password = origPassword
+
+	s.Logger.Println("## API Root Results")
+	for _, v := range roots {
+		if results[v] == 0 {
+			s.Logger.Println("== SUCCESS: API root", v, "passed all tests")
+		} else {
+			s.Logger.Println("== FAILURE: API root", v, "had", results[v], "problems")
+		}
+	}
+	s.Logger.Println("")
+}
+
+/*
+loadCredentials - This method will load the per API root credentials file if
+one was provided. The file is a JSON object keyed by API root path, for example
+{"/api1/": {"username": "user", "password": "pass"}}, or by the full URL for API
+roots on another server. It will return an integer representing the number of
+problems found.
+*/
+func (s *Suite) loadCredentials() (map[string]apiRootCredentials, int) {
+	credentials := make(map[string]apiRootCredentials)
+	if s.Settings.Credentials == "" {
+		return credentials, 0
+	}
+
+	data, err := ioutil.ReadFile(s.Settings.Credentials)
+	if err != nil {
+		s.Logger.Println("-- ERROR: Unable to read the credentials file", err)
+		return credentials, 1
+	}
+
+	var raw map[string]apiRootCredentials
+	if err := json.Unmarshal(data, &raw); err != nil {
+		s.Logger.Println("-- ERROR: Invalid credentials file", err)
+		return credentials, 1
+	}
+
+	for k, v := range raw {
+		if u, err := url.Parse(k); err == nil && u.IsAbs() {
+			credentials[u.Scheme+"://"+u.Host+normalizePath(u.Path)] = v
+			continue
+		}
+		credentials[normalizePath(k)] = v
+	}
+	return credentials, 0
+}
@@ -0,0 +1,232 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"sort"
+)
+
+/*
+filteringTestCase - This type holds a single filtering test case that was
+read from a test cases file. The path is relative to the read-only collection,
+for example objects/ or objects/<id>/. If the corpus is given, the test case
+is only run when the corpus file has that name, like indicators.json or
+corpus.json.
+*/
+type filteringTestCase struct {
+	Name     string            `json:"name"`
+	Corpus   string            `json:"corpus,omitempty"`
+	Path     string            `json:"path"`
+	Params   map[string]string `json:"params"`
+	Types    []string          `json:"types,omitempty"`
+	Expected []expectedVersion `json:"expected"`
+}
+
+/*
+expectedVersion - This type holds the ID and version of a single version of an
+object that a test case expects to be returned. The version is the modified
+timestamp of the object, or the created timestamp if it does not have one, like
+marking definitions, or empty if it has neither, like STIX Cyber-observable
+Objects.
+*/
+type expectedVersion struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+/*
+TestDataDrivenFilteringROCollection - This method will run the filtering test
+cases from the test cases file against the Read-Only collection. Each test case
+lists the URL parameters to send and the versions of the objects that must be
+returned. The content of each expected object is taken from the corpus file,
+so new filtering scenarios can be added without changing the test suite. By
+default the test cases are read from data/filtering.json and the corpus is
+data/indicators.json or data/corpus.json, depending on which one is loaded.
+*/
+func (s *Suite) TestDataDrivenFilteringROCollection() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Testing Data Driven Filtering Read-Only Collection")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	corpusFile := s.corpusFile()
+	corpus, problems := s.loadCorpus(corpusFile)
+	cases, p := s.loadTestCases()
+	problems += p
+	if problems != 0 {
+		s.Logger.Println("-- ERROR: Unable to load the corpus or the test cases, skipping the data driven filtering tests")
+		s.ProblemsFound += problems
+		s.printTestSummary()
+		return
+	}
+
+	objects := make(map[string]interface{})
+	for _, v := range corpus {
+		objects[objectKey(v)] = v
+	}
+
+	s.Logger.Println("## Start Data Driven Filtering Tests for RO Collections\n")
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/"
+	test := 0
+	for _, v := range cases {
+		if v.Corpus != "" && v.Corpus != filepath.Base(corpusFile) {
+			s.Logger.Debugln("++ Skipping test case", v.Name, "since it is for", v.Corpus)
+			continue
+		}
+		test++
+		s.setPath(path + v.Path)
+		s.testDataDrivenFiltering(test, v, objects)
+	}
+}
+
+/*
+testDataDrivenFiltering - This method will run a single test case. It will get
+every page of the objects at the current path using the URL parameters of the
+test case and compare them to the expected versions.
+*/
+func (s *Suite) testDataDrivenFiltering(test int, c filteringTestCase, objects map[string]interface{}) {
+	s.Logger.Printf("## Test DF-%02d: Test %s\n", test, c.Name)
+	s.Logger.Infoln("++ This test will run the test case", c.Name, "from", s.testCasesFile())
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	invalid := 0
+	var expected []interface{}
+	for _, v := range c.Expected {
+		k := versionKey(v.ID, v.Version)
+		o, found := objects[k]
+		if !found {
+			s.Logger.Println("-- ERROR: The test case expects", k, "which is not in the corpus")
+			invalid++
+			continue
+		}
+		expected = append(expected, o)
+	}
+
+	if invalid != 0 {
+		s.Logger.Println("-- ERROR: The test case is not valid for the corpus, skipping this test")
+		s.ProblemsFound += invalid
+		s.printTestSummary()
+		return
+	}
+
+	names := make([]string, 0, len(c.Params))
+	for k := range c.Params {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	params := url.Values{}
+	for _, k := range names {
+		params.Set(k, c.Params[k])
+		s.Logger.Infoln("++ Using", k, "of", c.Params[k])
+	}
+
+	all, problems := s.walkResource(resourceEnvelope, params, 0)
+	s.ProblemsFound += problems
+	s.ProblemsFound += s.compareObjects(expected, all.Objects)
+	if len(c.Types) != 0 {
+		s.ProblemsFound += s.checkObjectTypes(all.Objects, c.Types)
+	}
+
+	s.printTestSummary()
+}
+
+/*
+corpusFile - This method will return the name of the corpus file. If one was
+not given, the data file for the TestLab data that is loaded in the read-only
+collection is used.
+*/
+func (s *Suite) corpusFile() string {
+	if s.Settings.CorpusFile != "" {
+		return s.Settings.CorpusFile
+	}
+	if s.fullCorpusLoaded() {
+		return filepath.Join(s.Settings.DataDir, "corpus.json")
+	}
+	return filepath.Join(s.Settings.DataDir, "indicators.json")
+}
+
+/*
+testCasesFile - This method will return the name of the test cases file, which
+is data/filtering.json if one was not given.
+*/
+func (s *Suite) testCasesFile() string {
+	if s.Settings.TestCasesFile != "" {
+		return s.Settings.TestCasesFile
+	}
+	return filepath.Join(s.Settings.DataDir, "filtering.json")
+}
+
+/*
+loadCorpus - This method will read the STIX objects from the corpus file
+provided. The corpus file can be a STIX bundle or a TAXII envelope, like
+data/indicators.json. It will return the objects and an integer representing
+the number of problems found.
+*/
+func (s *Suite) loadCorpus(name string) ([]interface{}, int) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		s.Logger.Println("-- ERROR: Unable to read the corpus file", err)
+		return nil, 1
+	}
+
+	var raw struct {
+		Objects []STIXObject `json:"objects"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		s.Logger.Println("-- ERROR: Invalid corpus file", err)
+		return nil, 1
+	}
+
+	if len(raw.Objects) == 0 {
+		s.Logger.Println("-- ERROR: The corpus file", name, "does not contain any objects")
+		return nil, 1
+	}
+
+	objects := make([]interface{}, 0, len(raw.Objects))
+	for _, v := range raw.Objects {
+		objects = append(objects, v)
+	}
+	s.Logger.Infoln("++ Loaded", len(objects), "objects from the corpus file", name)
+	return objects, 0
+}
+
+/*
+loadTestCases - This method will read the filtering test cases from the test
+cases file. It will return the test cases and an integer representing the
+number of problems found.
+*/
+func (s *Suite) loadTestCases() ([]filteringTestCase, int) {
+	name := s.testCasesFile()
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		s.Logger.Println("-- ERROR: Unable to read the test cases file", err)
+		return nil, 1
+	}
+
+	var raw struct {
+		TestCases []filteringTestCase `json:"test_cases"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		s.Logger.Println("-- ERROR: Invalid test cases file", err)
+		return nil, 1
+	}
+
+	problems := 0
+	for i, v := range raw.TestCases {
+		if v.Name == "" || v.Path == "" {
+			s.Logger.Println("-- ERROR: Test case", i+1, "in the test cases file must have a name and a path")
+			problems++
+		}
+	}
+
+	s.Logger.Infoln("++ Loaded", len(raw.TestCases), "test cases from the test cases file", name)
+	return raw.TestCases, problems
+}
@@ -9,6 +9,8 @@ package suite
 import (
 	"encoding/json"
 	"io/ioutil"
+	"net/url"
+	"sort"
 
 	"github.com/freetaxii/libstix2/resources/discovery"
 )
@@ -26,33 +28,211 @@ func (s *Suite) TestDiscoveryService() {
 	s.setPath(s.Settings.Discovery)
 
 	s.basicEndpointTests()
-	s.getDiscoveryOutput()
+	o := s.getDiscoveryOutput()
+	if o == nil {
+		s.Logger.Println("++ Skipping the remaining discovery tests, the discovery resource could not be decoded\n")
+		return
+	}
+	s.testDiscoveryProperties(o)
+	s.testDiscoveryAPIRoot(o)
+	s.testDiscoveryURLs(o)
+	s.testDiscoveryExpected(o)
 }
 
-func (s *Suite) getDiscoveryOutput() {
+func (s *Suite) getDiscoveryOutput() *discovery.Discovery {
 	s.Logger.Println("## Test D1: Test Discovery Endpoint")
 	s.Logger.Infoln("++ This test will check to see if a proper discovery resource is returned")
 	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
 
-	s.startTest()
-	s.setAccept(s.FullMediaType)
-	s.enableAuth(s.Settings.Username, s.Settings.Password)
-
 	var o discovery.Discovery
-	resp, err := s.Client.Do(s.Req)
-	s.handleError(err)
-	defer resp.Body.Close()
-	s.ProblemsFound += s.checkResponseCode(resp.StatusCode, 200)
-
-	body, err := ioutil.ReadAll(resp.Body)
-	s.handleError(err)
-
-	jerr := json.Unmarshal(body, &o)
-	s.handleError(jerr)
+	if _, problems := s.getResource("Discovery", &o); problems != 0 {
+		s.ProblemsFound += problems
+		s.printTestSummary()
+		return nil
+	}
 
 	var data []byte
 	data, _ = json.MarshalIndent(o, "", "    ")
 	s.Logger.Println("++ Discovery Resource Returned:\n", string(data))
 
 	s.printTestSummary()
+	return &o
+}
+
+/*
+testDiscoveryProperties - This method will make sure the discovery resource has
+a title and that the default API root, if there is one, is also listed in
+api_roots.
+*/
+func (s *Suite) testDiscoveryProperties(o *discovery.Discovery) {
+	s.Logger.Println("## Test D2: Test Discovery Resource Properties")
+	s.Logger.Infoln("++ This test will check to see if the required title is present and the default API root is listed in api_roots")
+
+	if o.Title == "" {
+		s.Logger.Println("-- ERROR: Discovery resource is missing the required title property")
+		s.ProblemsFound++
+	}
+
+	if o.Default != "" {
+		found := false
+		for _, v := range o.APIRoots {
+			if v == o.Default {
+				found = true
+			}
+		}
+		if !found {
+			s.Logger.Println("-- ERROR: Discovery resource default", o.Default, "is not listed in api_roots")
+			s.ProblemsFound++
+		}
+	}
+
+	s.printTestSummary()
+}
+
+/*
+testDiscoveryAPIRoot - This method will make sure the API root that is being
+tested is listed in the api_roots of the discovery resource. Only the path is
+compared, since a TAXII Server behind a proxy can list its API roots using a
+host name that is different from the one used to reach it.
+*/
+func (s *Suite) testDiscoveryAPIRoot(o *discovery.Discovery) {
+	s.Logger.Println("## Test D3: Test Discovery Resource Lists API Root")
+	s.Logger.Infoln("++ This test will check to see if the API root", s.Settings.APIRoot, "is listed in api_roots")
+
+	found := false
+	for _, v := range o.APIRoots {
+		u, err := url.Parse(v)
+		if err != nil {
+			continue
+		}
+		ref := s.Req.URL.ResolveReference(u)
+		if ref.Path != s.Settings.APIRoot {
+			continue
+		}
+		found = true
+		if ref.Host != s.Req.URL.Host {
+			s.Logger.Println("++ API root", v, "is listed with the host", ref.Host, "but the TAXII Server was reached using", s.Req.URL.Host, "so only the path was compared")
+		}
+	}
+
+	if !found {
+		s.Logger.Println("-- ERROR: API root", s.Settings.APIRoot, "is not listed in the discovery resource api_roots")
+		s.ProblemsFound++
+	}
+
+	s.printTestSummary()
+}
+
+/*
+testDiscoveryURLs - This method will make sure every URL in the discovery
+resource is either an absolute HTTP URL or a valid relative path.
+*/
+func (s *Suite) testDiscoveryURLs(o *discovery.Discovery) {
+	s.Logger.Println("## Test D4: Test Discovery Resource URLs")
+	s.Logger.Infoln("++ This test will check to see if the default and api_roots values are absolute URLs or valid relative paths")
+
+	urls := o.APIRoots
+	if o.Default != "" {
+		urls = append([]string{o.Default}, urls...)
+	}
+
+	for _, v := range urls {
+		if !validResourceURL(v) {
+			s.Logger.Println("-- ERROR: Discovery resource URL", v, "is not an absolute URL or a valid relative path")
+			s.ProblemsFound++
+		} else {
+			s.Logger.Infoln("++ Discovery resource URL", v, "is valid")
+		}
+	}
+
+	s.printTestSummary()
+}
+
+/*
+testDiscoveryExpected - This method will compare the discovery resource to the
+expected discovery resource that was loaded from a file. This test only runs if
+a file was provided.
+*/
+func (s *Suite) testDiscoveryExpected(o *discovery.Discovery) {
+	if s.Settings.ExpectedDiscovery == "" {
+		return
+	}
+
+	s.Logger.Println("## Test D5: Test Discovery Resource Matches Expected")
+	s.Logger.Infoln("++ This test will compare the discovery resource to the one in", s.Settings.ExpectedDiscovery)
+
+	data, err := ioutil.ReadFile(s.Settings.ExpectedDiscovery)
+	if err != nil {
+		s.Logger.Println("-- ERROR: Unable to read the expected discovery resource", err)
+		s.ProblemsFound++
+		s.printTestSummary()
+		return
+	}
+
+	var e discovery.Discovery
+	if err := json.Unmarshal(data, &e); err != nil {
+		s.Logger.Println("-- ERROR: Invalid expected discovery resource", err)
+		s.ProblemsFound++
+		s.printTestSummary()
+		return
+	}
+
+	properties := []struct {
+		name     string
+		expected string
+		actual   string
+	}{
+		{"title", e.Title, o.Title},
+		{"description", e.Description, o.Description},
+		{"contact", e.Contact, o.Contact},
+		{"default", e.Default, o.Default},
+	}
+
+	for _, p := range properties {
+		if p.expected != p.actual {
+			s.Logger.Println("-- ERROR: Expected discovery", p.name, p.expected, "Got", p.actual)
+			s.ProblemsFound++
+		}
+	}
+
+	expectedRoots := append([]string(nil), e.APIRoots...)
+	actualRoots := append([]string(nil), o.APIRoots...)
+	sort.Strings(expectedRoots)
+	sort.Strings(actualRoots)
+	s.ProblemsFound += s.compareKeys(expectedRoots, actualRoots)
+
+	s.printTestSummary()
+}
+
+/*
+validResourceURL - This function will check to see if a URL from a TAXII
+resource is an absolute HTTP or HTTPS URL or a relative path.
+*/
+func validResourceURL(v string) bool {
+	u, err := url.Parse(v)
+	if err != nil || v == "" {
+		return false
+	}
+
+	if u.IsAbs() {
+		return (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+	}
+
+	return u.Host == "" && u.Path != "" && u.RawQuery == "" && u.Fragment == ""
+}
+
+/*
+getDiscoveryResource - This method will get the Discovery resource from the
+Discovery endpoint and return it. It will return an integer representing the
+number of problems found.
+*/
+func (s *Suite) getDiscoveryResource() (*discovery.Discovery, int) {
+	s.setPath(s.Settings.Discovery)
+	s.Logger.Infoln("++ Getting Discovery resource from:", s.Req.URL.Path)
+
+	var o discovery.Discovery
+	if _, problems := s.getResource("Discovery", &o); problems != 0 {
+		return nil, problems
+	}
+	return &o, 0
 }
@@ -0,0 +1,146 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"sort"
+)
+
+/*
+TestFidelityROCollection - This method will get each STIX Cyber-observable
+Object, and each object with extensions or custom x_ properties, from the
+Read-Only collection and make sure the server returned it exactly as it was
+loaded, without dropping or changing any property it does not know about.
+These tests need the full corpus from testData to be loaded.
+*/
+func (s *Suite) TestFidelityROCollection() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Testing Fidelity Read-Only Collection")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	if !s.fullCorpusLoaded() {
+		s.Logger.Println("++ Skipping the fidelity tests, only data/indicators.json is loaded in the read-only collection\n")
+		return
+	}
+
+	s.Logger.Println("## Start Fidelity Tests for RO Collections\n")
+	// Test each extension definition, and the malware with custom properties
+	// Test each cyber-observable object, including predefined and custom extensions
+	var data []STIXObject
+	data = append(data, GenerateExtensionData()...)
+	data = append(data, GenerateObservableData()...)
+
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/objects/"
+	for i, v := range data {
+		id, _ := v["id"].(string)
+		s.setPath(path + id + "/")
+		s.testFidelity(i+1, v)
+	}
+}
+
+/*
+testFidelity - This method will get a single object by its ID and compare every
+property, including nested properties in extensions, to the object that was
+loaded.
+*/
+func (s *Suite) testFidelity(test int, expected STIXObject) {
+	s.Logger.Printf("## Test FI-%02d: Test Fidelity Of %s\n", test, expected["id"])
+	s.Logger.Infoln("++ This test will check to see if the", expected["type"], "object is returned without any properties being dropped or changed")
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	params := url.Values{}
+	params.Set("match[version]", "all")
+
+	all, problems := s.walkResource(resourceEnvelope, params, 0)
+	s.ProblemsFound += problems
+
+	if len(all.Objects) != 1 {
+		s.Logger.Println("-- ERROR: Expected 1 object. Got", len(all.Objects))
+		s.ProblemsFound++
+	}
+
+	if len(all.Objects) > 0 {
+		data, err := json.Marshal(expected)
+		s.handleError(err)
+
+		var e, a map[string]interface{}
+		s.handleError(json.Unmarshal(data, &e))
+		if err := json.Unmarshal(all.Objects[0], &a); err != nil {
+			s.Logger.Println("-- ERROR: Object could not be decoded", err)
+			s.ProblemsFound++
+		} else {
+			s.ProblemsFound += s.compareFidelity("", e, a)
+		}
+	}
+
+	s.printTestSummary()
+}
+
+/*
+compareFidelity - This method will compare every property of the expected
+object to the returned object, following nested objects, and report each
+property that was dropped, changed, or added. It will return an integer
+representing the number of problems found.
+*/
+func (s *Suite) compareFidelity(prefix string, expected, actual map[string]interface{}) int {
+	problems := 0
+
+	names := make(map[string]bool)
+	for k := range expected {
+		names[k] = true
+	}
+	for k := range actual {
+		names[k] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for k := range names {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		property := k
+		if prefix != "" {
+			property = prefix + "." + k
+		}
+
+		e, eok := expected[k]
+		a, aok := actual[k]
+
+		switch {
+		case !aok:
+			s.Logger.Println("-- ERROR: Property", property, "was dropped")
+			problems++
+		case !eok:
+			s.Logger.Println("-- ERROR: Property", property, "was added")
+			problems++
+		default:
+			em, emok := e.(map[string]interface{})
+			am, amok := a.(map[string]interface{})
+			if emok && amok {
+				problems += s.compareFidelity(property, em, am)
+				continue
+			}
+
+			es, esok := e.(string)
+			as, asok := a.(string)
+			if esok && asok && sameTimestamp(es, as) {
+				continue
+			}
+
+			if !reflect.DeepEqual(e, a) {
+				s.Logger.Println("-- ERROR: Property", property, "was changed from", e, "to", a)
+				problems++
+			}
+		}
+	}
+
+	return problems
+}
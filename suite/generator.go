@@ -0,0 +1,430 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+CorpusOptions - This type holds the options that control the corpus that is
+made by GenerateCorpus. The same options, including the seed, always make the
+same corpus.
+*/
+type CorpusOptions struct {
+	Seed        int64
+	Objects     int
+	MaxVersions int
+	TypeMix     map[string]int
+	Start       time.Time
+	Spread      time.Duration
+}
+
+/*
+corpusTypes - These are the types of objects that GenerateCorpus can make, in
+the order they are used when picking a type.
+*/
+var corpusTypes = []string{
+	"attack-pattern",
+	"campaign",
+	"identity",
+	"indicator",
+	"malware",
+	"relationship",
+	"sighting",
+	"threat-actor",
+}
+
+/*
+corpusRelationships - These are the relationships GenerateCorpus can make
+between two types of objects. When none of them can be made yet, a related-to
+relationship is made instead.
+*/
+var corpusRelationships = []struct {
+	source       string
+	relationship string
+	target       string
+}{
+	{"indicator", "indicates", "attack-pattern"},
+	{"indicator", "indicates", "campaign"},
+	{"indicator", "indicates", "malware"},
+	{"indicator", "indicates", "threat-actor"},
+	{"malware", "uses", "attack-pattern"},
+	{"campaign", "attributed-to", "threat-actor"},
+	{"campaign", "uses", "malware"},
+	{"threat-actor", "uses", "attack-pattern"},
+	{"threat-actor", "uses", "malware"},
+	{"threat-actor", "targets", "identity"},
+}
+
+/*
+DefaultCorpusOptions - This function will return the options for a corpus of
+1000 objects with up to 3 versions each, spread over one year.
+*/
+func DefaultCorpusOptions() CorpusOptions {
+	return CorpusOptions{
+		Seed:        1,
+		Objects:     1000,
+		MaxVersions: 3,
+		TypeMix: map[string]int{
+			"attack-pattern": 10,
+			"campaign":       5,
+			"identity":       5,
+			"indicator":      30,
+			"malware":        10,
+			"relationship":   25,
+			"sighting":       10,
+			"threat-actor":   5,
+		},
+		Start:  time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Spread: 365 * 24 * time.Hour,
+	}
+}
+
+/*
+ParseTypeMix - This function will parse a type mix like
+"indicator=30,malware=10,relationship=20" where each number is the weight of
+that type of object.
+*/
+func ParseTypeMix(value string) (map[string]int, error) {
+	mix := make(map[string]int)
+	for _, v := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(v), "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid type mix entry %q, expected type=weight", v)
+		}
+		weight, err := strconv.Atoi(parts[1])
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight for type %s in the type mix", parts[0])
+		}
+		mix[parts[0]] = weight
+	}
+	return mix, nil
+}
+
+/*
+corpusGenerator - This type holds the state that is needed while a corpus is
+being made. The IDs of the objects that have been made are kept by type so
+relationships and sightings only reference objects that came before them.
+*/
+type corpusGenerator struct {
+	r       *rand.Rand
+	options CorpusOptions
+	creator string
+	ids     map[string][]string
+}
+
+/*
+GenerateCorpus - This function will make a corpus of valid, interlinked STIX
+2.1 objects and pass every version of every object to emit, one at a time,
+instead of returning them. The TLP marking
+definitions and the identity that created the objects are made first and are
+not counted in the number of objects. Each object gets between one and the
+maximum number of versions, which are passed to emit in order, and every
+reference points to an object that was made before it.
+*/
+func GenerateCorpus(options CorpusOptions, emit func(STIXObject) error) error {
+	if options.Objects < 1 {
+		return errors.New("the number of objects must be at least 1")
+	}
+	if options.MaxVersions < 1 {
+		return errors.New("the maximum number of versions must be at least 1")
+	}
+	if options.Spread < 0 {
+		return errors.New("the timestamp spread can not be negative")
+	}
+
+	total := 0
+	for k, v := range options.TypeMix {
+		if !isCorpusType(k) {
+			return fmt.Errorf("type %s is not supported, use one of %s", k, strings.Join(corpusTypes, ","))
+		}
+		total += v
+	}
+	if total == 0 {
+		return errors.New("the type mix must give at least one type a weight")
+	}
+
+	g := corpusGenerator{
+		r:       rand.New(rand.NewSource(options.Seed)),
+		options: options,
+		ids:     make(map[string][]string),
+	}
+
+	for _, v := range GenerateMarkingDefinitionData() {
+		if err := emit(stixObjectFrom(v)); err != nil {
+			return err
+		}
+	}
+
+	g.creator = g.newID("identity")
+	creator := STIXObject{
+		"type":           "identity",
+		"spec_version":   "2.1",
+		"id":             g.creator,
+		"created":        formatTimestamp(options.Start),
+		"modified":       formatTimestamp(options.Start),
+		"name":           "TestLab Generated Data",
+		"identity_class": "organization",
+	}
+	if err := emit(creator); err != nil {
+		return err
+	}
+	g.ids["identity"] = append(g.ids["identity"], g.creator)
+
+	for i := 1; i <= options.Objects; i++ {
+		o := g.newObject(g.pickType(total), i)
+		if err := g.emitVersions(o, emit); err != nil {
+			return err
+		}
+		t := o["type"].(string)
+		g.ids[t] = append(g.ids[t], o["id"].(string))
+	}
+
+	return nil
+}
+
+/*
+pickType - This method will pick the type of the next object using the weights
+in the type mix. Relationships and sightings are only picked once there are
+objects for them to reference, otherwise an indicator is made.
+*/
+func (g *corpusGenerator) pickType(total int) string {
+	n := g.r.Intn(total)
+	picked := corpusTypes[0]
+	for _, v := range corpusTypes {
+		if n < g.options.TypeMix[v] {
+			picked = v
+			break
+		}
+		n -= g.options.TypeMix[v]
+	}
+
+	switch picked {
+	case "relationship":
+		if g.countSDOs(false) < 2 {
+			return "indicator"
+		}
+	case "sighting":
+		if g.countSDOs(true) == 0 {
+			return "indicator"
+		}
+	}
+	return picked
+}
+
+/*
+newObject - This method will make the first version of an object of the type
+provided.
+*/
+func (g *corpusGenerator) newObject(objectType string, n int) STIXObject {
+	created := g.options.Start
+	if g.options.Spread > 0 {
+		created = created.Add(time.Duration(g.r.Int63n(int64(g.options.Spread/time.Millisecond)+1)) * time.Millisecond)
+	}
+	markings := []string{TLPWhite, TLPGreen}
+
+	o := STIXObject{
+		"type":                objectType,
+		"spec_version":        "2.1",
+		"id":                  g.newID(objectType),
+		"created_by_ref":      g.creator,
+		"created":             formatTimestamp(created),
+		"modified":            formatTimestamp(created),
+		"object_marking_refs": []interface{}{markings[g.r.Intn(len(markings))]},
+	}
+
+	name := fmt.Sprintf("TestLab Generated %s %d", objectType, n)
+
+	switch objectType {
+	case "attack-pattern":
+		o["name"] = name
+		o["kill_chain_phases"] = []interface{}{
+			map[string]interface{}{"kill_chain_name": "mitre-attack", "phase_name": "initial-access"},
+		}
+	case "campaign":
+		o["name"] = name
+		o["first_seen"] = formatTimestamp(created)
+	case "identity":
+		o["name"] = name
+		o["identity_class"] = "organization"
+	case "indicator":
+		o["name"] = name
+		o["indicator_types"] = []interface{}{"malicious-activity"}
+		o["pattern"] = fmt.Sprintf("[ipv4-addr:value = '10.%d.%d.%d']", g.r.Intn(256), g.r.Intn(256), g.r.Intn(256))
+		o["pattern_type"] = "stix"
+		o["valid_from"] = formatTimestamp(created)
+	case "malware":
+		o["name"] = name
+		o["malware_types"] = []interface{}{"remote-access-trojan"}
+		o["is_family"] = g.r.Intn(2) == 0
+	case "threat-actor":
+		o["name"] = name
+		o["threat_actor_types"] = []interface{}{"crime-syndicate"}
+	case "relationship":
+		source, relationship, target := g.pickRelationship()
+		o["relationship_type"] = relationship
+		o["source_ref"] = source
+		o["target_ref"] = target
+	case "sighting":
+		identities := g.ids["identity"]
+		o["sighting_of_ref"] = g.pickSDO(true)
+		o["where_sighted_refs"] = []interface{}{identities[g.r.Intn(len(identities))]}
+		o["count"] = g.r.Intn(100) + 1
+		o["first_seen"] = formatTimestamp(created)
+		o["last_seen"] = formatTimestamp(created.Add(time.Duration(g.r.Intn(86400)) * time.Second))
+	}
+
+	return o
+}
+
+/*
+emitVersions - This method will pass between one and the maximum number of
+versions of the object to emit. Each new version is modified between one second
+and one day after the version before it.
+*/
+func (g *corpusGenerator) emitVersions(o STIXObject, emit func(STIXObject) error) error {
+	versions := g.r.Intn(g.options.MaxVersions) + 1
+	modified, _ := time.Parse(time.RFC3339Nano, o["created"].(string))
+
+	for v := 1; v <= versions; v++ {
+		if v > 1 {
+			modified = modified.Add(time.Duration(g.r.Int63n(int64(24*time.Hour/time.Millisecond))+1000) * time.Millisecond)
+		}
+
+		version := make(STIXObject, len(o)+1)
+		for k, p := range o {
+			version[k] = p
+		}
+		version["modified"] = formatTimestamp(modified)
+		version["description"] = fmt.Sprintf("This is version %d of %d", v, versions)
+
+		if err := emit(version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+pickRelationship - This method will pick a relationship that can be made
+between the objects that have been made so far.
+*/
+func (g *corpusGenerator) pickRelationship() (string, string, string) {
+	var possible []int
+	for i, v := range corpusRelationships {
+		if len(g.sdoIDs(v.source)) != 0 && len(g.sdoIDs(v.target)) != 0 {
+			possible = append(possible, i)
+		}
+	}
+
+	if len(possible) == 0 {
+		source := g.pickSDO(false)
+		target := g.pickSDO(false)
+		for target == source {
+			target = g.pickSDO(false)
+		}
+		return source, "related-to", target
+	}
+
+	p := corpusRelationships[possible[g.r.Intn(len(possible))]]
+	sources := g.sdoIDs(p.source)
+	targets := g.sdoIDs(p.target)
+	return sources[g.r.Intn(len(sources))], p.relationship, targets[g.r.Intn(len(targets))]
+}
+
+/*
+sdoIDs - This method will return the IDs of the STIX Domain Objects of the
+type provided that have been made so far. The identity that created the
+objects is left out.
+*/
+func (g *corpusGenerator) sdoIDs(objectType string) []string {
+	if objectType == "identity" {
+		return g.ids["identity"][1:]
+	}
+	return g.ids[objectType]
+}
+
+/*
+countSDOs - This method will return the number of STIX Domain Objects that have
+been made so far. When sighted is true the identities are left out since they
+are where an object was sighted and not what was sighted.
+*/
+func (g *corpusGenerator) countSDOs(sighted bool) int {
+	count := 0
+	for _, v := range corpusTypes {
+		if v == "relationship" || v == "sighting" || (sighted && v == "identity") {
+			continue
+		}
+		count += len(g.sdoIDs(v))
+	}
+	return count
+}
+
+/*
+pickSDO - This method will pick one of the STIX Domain Objects that have been
+made so far, as counted by countSDOs.
+*/
+func (g *corpusGenerator) pickSDO(sighted bool) string {
+	n := g.r.Intn(g.countSDOs(sighted))
+	for _, v := range corpusTypes {
+		if v == "relationship" || v == "sighting" || (sighted && v == "identity") {
+			continue
+		}
+		ids := g.sdoIDs(v)
+		if n < len(ids) {
+			return ids[n]
+		}
+		n -= len(ids)
+	}
+	return ""
+}
+
+/*
+newID - This method will make a random version 4 UUID based STIX ID from the
+seeded random number generator, so the same seed always makes the same IDs.
+*/
+func (g *corpusGenerator) newID(objectType string) string {
+	// Reading from a math/rand generator never fails.
+	u, _ := readUUID(g.r)
+	return objectType + "--" + u
+}
+
+/*
+isCorpusType - This function will return true if GenerateCorpus can make
+objects of the type provided.
+*/
+func isCorpusType(objectType string) bool {
+	i := sort.SearchStrings(corpusTypes, objectType)
+	return i < len(corpusTypes) && corpusTypes[i] == objectType
+}
+
+/*
+stixObjectFrom - This function will convert a STIX object of any type to a
+STIXObject.
+*/
+func stixObjectFrom(v interface{}) STIXObject {
+	var o STIXObject
+	data, _ := json.Marshal(v)
+	json.Unmarshal(data, &o)
+	return o
+}
+
+/*
+formatTimestamp - This function will format a time as a STIX timestamp with
+millisecond precision.
+*/
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
@@ -5,3 +5,158 @@
 // tree.
 
 package suite
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+getManifestRecords - This method will get the manifest records for every
+version of every object in the collection at the path provided. The records
+are returned in the order the server sent them. It will return an integer
+representing the number of problems found.
+*/
+func (s *Suite) getManifestRecords(collectionPath string) ([]manifestRecord, int) {
+	values := url.Values{}
+	values.Set("match[version]", "all")
+	return s.walkManifestRecords(collectionPath, values)
+}
+
+/*
+getAllManifestRecords - This method will get the manifest records for every
+version of every spec version of every object in the collection at the path
+provided. Without the spec version filter the server only returns the latest
+spec version of each object. It will return an integer representing the number
+of problems found.
+*/
+func (s *Suite) getAllManifestRecords(collectionPath string) ([]manifestRecord, int) {
+	values := url.Values{}
+	values.Set("match[version]", "all")
+	values.Set("match[spec_version]", "2.0,2.1")
+	return s.walkManifestRecords(collectionPath, values)
+}
+
+/*
+walkManifestRecords - This method will get every page of the manifest of the
+collection at the path provided using the URL parameters provided. It will
+return an integer representing the number of problems found.
+*/
+func (s *Suite) walkManifestRecords(collectionPath string, values url.Values) ([]manifestRecord, int) {
+	var records []manifestRecord
+
+	s.setPath(collectionPath + "manifest/")
+	s.Logger.Infoln("++ Getting manifest records from:", s.Req.URL.Path)
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+	s.Req.URL.RawQuery = values.Encode()
+
+	for pages := 1; ; pages++ {
+		resp := s.doRequest()
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		s.handleError(err)
+
+		if p := s.checkResponseCode(resp.StatusCode, 200); p != 0 {
+			return records, p
+		}
+
+		var m manifestResource
+		if err := json.Unmarshal(body, &m); err != nil {
+			s.Logger.Println("-- ERROR: Invalid manifest resource returned", err)
+			return records, 1
+		}
+		records = append(records, m.Objects...)
+
+		if !m.More {
+			return records, 0
+		}
+
+		last := resp.Header.Get("X-TAXII-Date-Added-Last")
+		if last == "" || len(m.Objects) == 0 || pages >= paginationMaxPages {
+			s.Logger.Println("-- ERROR: Unable to get the next page of the manifest")
+			return records, 1
+		}
+		values.Set("added_after", last)
+		s.Req.URL.RawQuery = values.Encode()
+	}
+}
+
+/*
+fullCorpusLoaded - This method will look at the manifest of the read-only
+collection to see if the full TestLab corpus from testData was loaded, instead
+of just data/indicators.json.
+*/
+func (s *Suite) fullCorpusLoaded() bool {
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/"
+	records, _ := s.getManifestRecords(path)
+	return containsFixtureObjects(records)
+}
+
+/*
+containsFixtureObjects - This function will return true if any of the objects
+in the full TestLab corpus that are not indicators are in the manifest records.
+*/
+func containsFixtureObjects(records []manifestRecord) bool {
+	var keys []string
+	for _, v := range otherFixtureObjects() {
+		keys = append(keys, objectKey(v))
+	}
+
+	others := make(map[string]bool)
+	for _, v := range fillUnversionedKeys(keys, records) {
+		others[v] = true
+	}
+	for _, v := range records {
+		if others[versionKey(v.ID, v.Version)] {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+fillUnversionedKeys - This function will fill in the version of the keys of
+objects that have neither a modified nor a created timestamp, like STIX
+Cyber-observable Objects. The manifest uses the date added value as the version
+of these objects, so it is taken from the manifest record with the same ID.
+Every key that is returned is normalized with versionKey.
+*/
+func fillUnversionedKeys(keys []string, records []manifestRecord) []string {
+	versions := make(map[string]string)
+	for _, v := range records {
+		versions[v.ID] = v.Version
+	}
+
+	filled := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts := strings.SplitN(k, " version ", 2)
+		id, version := parts[0], ""
+		if len(parts) == 2 {
+			version = parts[1]
+		}
+		if version == "" {
+			version = versions[id]
+		}
+		filled = append(filled, versionKey(id, version))
+	}
+	return filled
+}
+
+/*
+sortRecordsByDateAdded - This function will sort manifest records in ascending
+date added order, which is the order TAXII requires servers to use.
+*/
+func sortRecordsByDateAdded(records []manifestRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		ti, _ := time.Parse(time.RFC3339Nano, records[i].DateAdded)
+		tj, _ := time.Parse(time.RFC3339Nano, records[j].DateAdded)
+		return ti.Before(tj)
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// maxContentLengthTestLimit is the largest max_content_length that will be
+// tested. Sending anything larger would take longer than the HTTP client
+// timeout allows.
+const maxContentLengthTestLimit = 10 * 1024 * 1024
+
+/*
+TestMaxContentLengthRWCollection - This method will read the max_content_length
+from the API Root resource and POST envelopes that are just under and just over
+that size to the objects endpoint of the Read-Write collection.
+*/
+func (s *Suite) TestMaxContentLengthRWCollection() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Testing Max Content Length Read-Write Collection")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	if s.writeTestsNotApplicable() {
+		return
+	}
+
+	s.Logger.Println("## Start Max Content Length Tests for RW Collections\n")
+	// Test an envelope one byte under max_content_length
+	// Test an envelope one byte over max_content_length
+	root, problems := s.getAPIRootResource()
+	if problems != 0 || root == nil || root.MaxContentLength <= 0 {
+		s.Logger.Println("-- ERROR: Unable to get a valid max_content_length from the API Root, skipping the max content length tests")
+		s.ProblemsFound += problems
+		if problems == 0 {
+			s.ProblemsFound++
+		}
+		s.printTestSummary()
+		return
+	}
+	s.Logger.Infoln("++ API Root max_content_length:", root.MaxContentLength)
+
+	if root.MaxContentLength+1 > maxContentLengthTestLimit {
+		s.Logger.Println("++ Skipping the max content length tests, max_content_length", root.MaxContentLength, "is larger than the", maxContentLengthTestLimit, "bytes the tests will send\n")
+		return
+	}
+
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadWrite + "/objects/"
+	s.setPath(path)
+
+	s.testMaxContentLength01(root.MaxContentLength - 1)
+	s.testMaxContentLength02(root.MaxContentLength + 1)
+}
+
+/*
+testMaxContentLength01 - This method will POST an envelope that is one byte
+smaller than max_content_length and make sure it is accepted.
+*/
+func (s *Suite) testMaxContentLength01(size int) {
+	s.Logger.Println("## Test MC-01: Test Envelope Under Max Content Length")
+	s.Logger.Infoln("++ This test will POST an envelope that is", size, "bytes and check to see if a 202 status code is returned")
+
+	data := s.newSizedEnvelope(size)
+	if data == nil {
+		s.Logger.Println("++ Skipping this test, max_content_length is too small to hold an envelope with one indicator\n")
+		return
+	}
+	s.testPostContentResponse(s.FullMediaType, data, 202)
+}
+
+/*
+testMaxContentLength02 - This method will POST an envelope that is one byte
+larger than max_content_length and make sure it is rejected.
+*/
+func (s *Suite) testMaxContentLength02(size int) {
+	s.Logger.Println("## Test MC-02: Test Envelope Over Max Content Length")
+	s.Logger.Infoln("++ This test will POST an envelope that is", size, "bytes and check to see if a 413 status code is returned")
+
+	data := s.newSizedEnvelope(size)
+	if data == nil {
+		s.Logger.Println("++ Skipping this test, max_content_length is too small to hold an envelope with one indicator\n")
+		return
+	}
+	s.testPostContentResponse(s.FullMediaType, data, 413)
+}
+
+/*
+newSizedEnvelope - This method will return an envelope containing a single new
+indicator that is exactly the size provided. The description of the indicator
+is padded to reach the size. It will return nil if the envelope can not be made
+that small.
+*/
+func (s *Suite) newSizedEnvelope(size int) []byte {
+	i := GenerateWriteIndicator()
+	i.SetDescription("a")
+
+	var e envelopeResource
+	e.Objects = append(e.Objects, i)
+
+	data, err := json.Marshal(e)
+	s.handleError(err)
+
+	padding := size - len(data)
+	if padding < 0 {
+		return nil
+	}
+
+	i.SetDescription(strings.Repeat("a", padding+1))
+	data, err = json.Marshal(e)
+	s.handleError(err)
+	return data
+}
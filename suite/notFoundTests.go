@@ -0,0 +1,113 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+/*
+TestNotFound - This method will request resources that do not exist at each
+level of the URL hierarchy and make sure a 404 status code and a valid TAXII
+error message are returned. It will also make sure an object from the
+Read-Only collection can not be read through the Read-Write collection.
+*/
+func (s *Suite) TestNotFound() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Testing Unknown Resources")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	s.Logger.Println("## Start Not Found Tests\n")
+	// Test unknown API root
+	// Test unknown collection ID
+	// Test malformed collection ID
+	// Test unknown object ID
+	// Test object ID from another collection
+	allIndicators := GenerateIndicatorData()
+
+	s.setPath("/" + newUUID() + "/")
+	s.testNotFound01()
+
+	s.setPath(s.Settings.APIRoot + "collections/" + newUUID() + "/")
+	s.testNotFound02()
+
+	s.setPath(s.Settings.APIRoot + "collections/not-a-collection-id/")
+	s.testNotFound03()
+
+	s.setPath(s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/objects/indicator--" + newUUID() + "/")
+	s.testNotFound04()
+
+	s.setPath(s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadWrite + "/objects/" + allIndicators[0].ID + "/")
+	s.testNotFound05()
+}
+
+/*
+testNotFound01 - This method will request an API root that does not exist.
+*/
+func (s *Suite) testNotFound01() {
+	s.Logger.Println("## Test NF-01: Test Unknown API Root")
+	s.Logger.Infoln("++ This test will request an API root that does not exist and check to see if a 404 status code is returned")
+	s.testNotFoundResponse()
+}
+
+/*
+testNotFound02 - This method will request a collection that does not exist.
+*/
+func (s *Suite) testNotFound02() {
+	s.Logger.Println("## Test NF-02: Test Unknown Collection ID")
+	s.Logger.Infoln("++ This test will request a collection ID that does not exist and check to see if a 404 status code is returned")
+	s.testNotFoundResponse()
+}
+
+/*
+testNotFound03 - This method will request a collection using an ID that is not
+a UUID.
+*/
+func (s *Suite) testNotFound03() {
+	s.Logger.Println("## Test NF-03: Test Malformed Collection ID")
+	s.Logger.Infoln("++ This test will request a collection ID that is not a valid UUID and check to see if a 404 status code is returned")
+	s.testNotFoundResponse()
+}
+
+/*
+testNotFound04 - This method will request an object that does not exist in the
+read-only collection.
+*/
+func (s *Suite) testNotFound04() {
+	s.Logger.Println("## Test NF-04: Test Unknown Object ID")
+	s.Logger.Infoln("++ This test will request an object ID that does not exist in the read-only collection and check to see if a 404 status code is returned")
+	s.testNotFoundResponse()
+}
+
+/*
+testNotFound05 - This method will request an object that exists in the
+read-only collection by using the read-write collection. This test is not
+applicable to a read-only implementation.
+*/
+func (s *Suite) testNotFound05() {
+	s.Logger.Println("## Test NF-05: Test Object ID From Another Collection")
+	if s.writeTestsNotApplicable() {
+		return
+	}
+	s.Logger.Infoln("++ This test will request an object ID from the read-only collection using the read-write collection and check to see if a 404 status code is returned")
+	s.testNotFoundResponse()
+}
+
+/*
+testNotFoundResponse - This method is used by the not found tests. It will make
+a request to the current path and make sure a 404 status code is returned. The
+error message is checked when the request is made.
+*/
+func (s *Suite) testNotFoundResponse() {
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+
+	resp := s.doRequest()
+	defer resp.Body.Close()
+	s.ProblemsFound += s.checkResponseCode(resp.StatusCode, 404)
+
+	s.printTestSummary()
+}
@@ -6,27 +6,23 @@
 
 package suite
 
-import (
-	"github.com/freetaxii/libstix2/objects"
-	"github.com/freetaxii/libstix2/objects/bundle"
-	"github.com/freetaxii/libstix2/objects/indicator"
-)
-
 /*
 TestObjectsServiceROCollection - This method will perform all of the standard tests
 against the Read-Only Objects endpoint. It will also check to make sure the
 output from the GET request is correct and will echo the output to the logs.
+If the full corpus from testData is loaded, the other STIX types are tested too.
 */
 func (s *Suite) TestObjectsServiceROCollection() {
 	s.Logger.Println("## ---------------------------------------------------------")
 	s.Logger.Println("## Testing Objects Service Read-Only Collection")
 	s.Logger.Println("## ---------------------------------------------------------")
 
+	fullCorpus := s.fullCorpusLoaded()
 	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/objects/"
 	s.setPath(path)
 
 	s.basicEndpointTests()
-	s.basicIndicatorFilteringTestsObjectsRO()
+	s.basicIndicatorFilteringTestsObjectsRO(fullCorpus)
 }
 
 /*
@@ -46,64 +42,3 @@ func (s *Suite) TestObjectServiceROCollection() {
 	s.basicEndpointTests()
 	s.basicIndicatorFilteringTestsObjectRO()
 }
-
-/*
-testSortOrder01 - This method will get all indicators from the read-only
-collection and make sure they are all correct.
-*/
-func (s *Suite) testSortOrder01() {
-	s.Logger.Println("## Test SO-01: Test Sort Order")
-	if s.Verbose {
-		s.Logger.Println("++ This test will check to see if the sort order is correct for indicators returned from the read-only collection")
-		s.Logger.Println("++ Calling Path:", s.Req.URL.Path)
-	}
-
-	s.startTest()
-	s.setAccept(s.FullMediaType)
-	s.enableAuth(s.Settings.Username, s.Settings.Password)
-
-	resp, err := s.Client.Do(s.Req)
-	s.handleError(err)
-	defer resp.Body.Close()
-	s.ProblemsFound += s.checkResponseCode(resp.StatusCode, 200)
-
-	b, err := bundle.DecodeRaw(resp.Body)
-	if err != nil {
-		s.Logger.Println("-- ERROR: Invalid bundle returned", err)
-		s.ProblemsFound++
-		s.printTestSummary()
-		return
-	}
-
-	allIndicators := GenerateIndicatorData()
-	// This first test will only have 2 indicators
-	indicators := []indicator.Indicator{allIndicators[4], allIndicators[5]}
-
-	for index, v := range b.Objects {
-
-		// Make a first pass to decode just the object type value. Once we have this
-		// value we can easily make a second pass and decode the rest of the object.
-		stixtype, err := objects.DecodeType(v)
-		if err != nil {
-			// We should probably log the error here
-			continue
-		}
-
-		switch stixtype {
-		case "indicator":
-			o, _, err := indicator.Decode(v)
-			if err != nil {
-				// We should probably log the error here
-				continue
-			}
-
-			// Test sort order.
-			if o.ID != indicators[index].ID {
-				s.Logger.Println("-- ERROR: Sort order for returned data is wrong needs to be ascending")
-				s.ProblemsFound++
-				continue
-			}
-		}
-	}
-	s.printTestSummary()
-}
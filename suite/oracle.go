@@ -0,0 +1,354 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+oracleRecord - This type holds a single version of an object in the oracle
+along with the values that the TAXII filters are applied to. The version is
+the version from the manifest, which is the date added value for objects that
+do not have a modified or created timestamp, and versioned is false for them.
+*/
+type oracleRecord struct {
+	key         string
+	versioned   bool
+	id          string
+	objectType  string
+	specVersion string
+	version     string
+	versionTime time.Time
+	dateAdded   time.Time
+	object      interface{}
+}
+
+/*
+collectionOracle - This type is an in-memory model of a TAXII 2.1 collection.
+It holds every version of every object in the collection in ascending date
+added order and computes the result the server needs to return for a query.
+*/
+type collectionOracle struct {
+	records      []oracleRecord
+	ids          []string
+	types        []string
+	specVersions []string
+}
+
+/*
+oracleQuery - This type holds the filters of a single query. Empty values are
+not sent to the server.
+*/
+type oracleQuery struct {
+	ids          []string
+	types        []string
+	versions     []string
+	specVersions []string
+	addedAfter   string
+	limit        int
+}
+
+/*
+newCollectionOracle - This function will create an oracle from the objects in
+the corpus. Only the date added value of each version is taken from the
+manifest records of the collection the corpus was loaded in to, since the
+server assigns it. The keys of the versions in the corpus that are not in the
+manifest are returned so they can be reported.
+*/
+func newCollectionOracle(corpus []interface{}, records []manifestRecord) (*collectionOracle, []string) {
+	dateAdded := make(map[string]string)
+	unversioned := make(map[string]string)
+	for _, v := range records {
+		dateAdded[versionKey(v.ID, v.Version)] = v.DateAdded
+		unversioned[v.ID] = v.DateAdded
+	}
+
+	var o collectionOracle
+	var missing []string
+	ids := make(map[string]bool)
+	types := make(map[string]bool)
+	specVersions := make(map[string]bool)
+
+	for _, object := range corpus {
+		var h stixObjectHeader
+		data, _ := json.Marshal(object)
+		json.Unmarshal(data, &h)
+		if h.SpecVersion == "" {
+			h.SpecVersion = "2.0"
+		}
+
+		r := oracleRecord{
+			versioned:   h.version() != "",
+			id:          h.ID,
+			objectType:  h.ObjectType,
+			specVersion: h.SpecVersion,
+			version:     h.version(),
+			object:      object,
+		}
+
+		added, found := dateAdded[versionKey(h.ID, h.version())]
+		if !r.versioned {
+			// Objects without a modified or created timestamp, like STIX
+			// Cyber-observable Objects, only have one version and the manifest
+			// uses the date added value as its version.
+			added, found = unversioned[h.ID]
+			r.version = added
+		}
+		if !found {
+			missing = append(missing, versionKey(h.ID, h.version()))
+			continue
+		}
+		r.key = versionKey(h.ID, r.version)
+		r.versionTime, _ = time.Parse(time.RFC3339Nano, r.version)
+		r.dateAdded, _ = time.Parse(time.RFC3339Nano, added)
+		o.records = append(o.records, r)
+
+		if !ids[r.id] {
+			ids[r.id] = true
+			o.ids = append(o.ids, r.id)
+		}
+		if !types[r.objectType] {
+			types[r.objectType] = true
+			o.types = append(o.types, r.objectType)
+		}
+		if !specVersions[r.specVersion] {
+			specVersions[r.specVersion] = true
+			o.specVersions = append(o.specVersions, r.specVersion)
+		}
+	}
+
+	sort.SliceStable(o.records, func(i, j int) bool {
+		return o.records[i].dateAdded.Before(o.records[j].dateAdded)
+	})
+	sort.Strings(o.ids)
+	sort.Strings(o.types)
+	sort.Strings(o.specVersions)
+	return &o, missing
+}
+
+/*
+query - This method will return the records that match the query in the order
+the server needs to return them. The ID, type, and spec version filters are
+applied first, where no spec version filter means the latest spec version of
+each object. The version filter then selects from the versions that are left,
+where no version filter means the last version. Last, added_after removes the
+records that were not added after the time given. A limit does not change the
+result, it only splits it in to pages.
+*/
+func (o *collectionOracle) query(q oracleQuery) []oracleRecord {
+	ids := stringSet(q.ids)
+	types := stringSet(q.types)
+	specVersions := stringSet(q.specVersions)
+
+	latestSpec := make(map[string]string)
+	for _, r := range o.records {
+		if r.specVersion > latestSpec[r.id] {
+			latestSpec[r.id] = r.specVersion
+		}
+	}
+
+	var matched []oracleRecord
+	for _, r := range o.records {
+		if len(ids) != 0 && !ids[r.id] {
+			continue
+		}
+		if len(types) != 0 && !types[r.objectType] {
+			continue
+		}
+		if len(specVersions) == 0 && r.specVersion != latestSpec[r.id] {
+			continue
+		}
+		if len(specVersions) != 0 && !specVersions[r.specVersion] {
+			continue
+		}
+		matched = append(matched, r)
+	}
+
+	first := make(map[string]oracleRecord)
+	last := make(map[string]oracleRecord)
+	for _, r := range matched {
+		if f, found := first[r.id]; !found || r.versionTime.Before(f.versionTime) {
+			first[r.id] = r
+		}
+		if l, found := last[r.id]; !found || r.versionTime.After(l.versionTime) {
+			last[r.id] = r
+		}
+	}
+
+	versions := q.versions
+	if len(versions) == 0 {
+		versions = []string{"last"}
+	}
+
+	var addedAfter time.Time
+	if q.addedAfter != "" {
+		addedAfter, _ = time.Parse(time.RFC3339Nano, q.addedAfter)
+	}
+
+	var result []oracleRecord
+	for _, r := range matched {
+		selected := false
+		for _, v := range versions {
+			switch v {
+			case "all":
+				selected = true
+			case "first":
+				selected = selected || first[r.id].key == r.key
+			case "last":
+				selected = selected || last[r.id].key == r.key
+			default:
+				selected = selected || sameTimestamp(v, r.version)
+			}
+		}
+		if !selected {
+			continue
+		}
+		if q.addedAfter != "" && !r.dateAdded.After(addedAfter) {
+			continue
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+/*
+randomQuery - This method will build a query using values from the records in
+the oracle. Each filter is used about half of the time so that queries with
+one filter and queries that combine several filters are both tested.
+*/
+func (o *collectionOracle) randomQuery(r *rand.Rand) oracleQuery {
+	var q oracleQuery
+
+	if r.Intn(2) == 0 {
+		q.ids = randomValues(r, o.ids, 3)
+	}
+
+	if r.Intn(2) == 0 {
+		q.types = randomValues(r, o.types, 2)
+	}
+
+	if r.Intn(2) == 0 {
+		var versioned []string
+		for _, v := range o.records {
+			// Objects without a version of their own can not be requested by
+			// version.
+			if v.versioned {
+				versioned = append(versioned, v.version)
+			}
+		}
+		choices := []string{"all", "first", "last"}
+		if len(versioned) != 0 {
+			choices = append(choices, versioned[r.Intn(len(versioned))])
+		}
+		q.versions = randomValues(r, choices, 2)
+	}
+
+	if r.Intn(2) == 0 {
+		q.specVersions = randomValues(r, o.specVersions, len(o.specVersions))
+	}
+
+	if r.Intn(2) == 0 && len(o.records) != 0 {
+		q.addedAfter = o.records[r.Intn(len(o.records))].dateAdded.Format(time.RFC3339Nano)
+	}
+
+	if r.Intn(2) == 0 {
+		q.limit = r.Intn(5) + 1
+	}
+
+	return q
+}
+
+/*
+params - This method will return the URL parameters for the query. The limit
+is not included since it is sent by walkResource.
+*/
+func (q oracleQuery) params() url.Values {
+	params := url.Values{}
+	if len(q.ids) != 0 {
+		params.Set("match[id]", strings.Join(q.ids, ","))
+	}
+	if len(q.types) != 0 {
+		params.Set("match[type]", strings.Join(q.types, ","))
+	}
+	if len(q.versions) != 0 {
+		params.Set("match[version]", strings.Join(q.versions, ","))
+	}
+	if len(q.specVersions) != 0 {
+		params.Set("match[spec_version]", strings.Join(q.specVersions, ","))
+	}
+	if q.addedAfter != "" {
+		params.Set("added_after", q.addedAfter)
+	}
+	return params
+}
+
+/*
+String - This method will return a short description of the query that is
+used in the test output.
+*/
+func (q oracleQuery) String() string {
+	var parts []string
+	if len(q.ids) != 0 {
+		parts = append(parts, strconv.Itoa(len(q.ids))+" ID")
+	}
+	if len(q.types) != 0 {
+		parts = append(parts, "Type "+strings.Join(q.types, ","))
+	}
+	if len(q.versions) != 0 {
+		parts = append(parts, "Version "+strings.Join(q.versions, ","))
+	}
+	if len(q.specVersions) != 0 {
+		parts = append(parts, "Spec Version "+strings.Join(q.specVersions, ","))
+	}
+	if q.addedAfter != "" {
+		parts = append(parts, "Added After")
+	}
+	if q.limit != 0 {
+		parts = append(parts, "Limit "+strconv.Itoa(q.limit))
+	}
+	if len(parts) == 0 {
+		return "No Filtering"
+	}
+	return strings.Join(parts, " and ")
+}
+
+/*
+randomValues - This function will return between one and max of the values
+provided, without repeating any of them.
+*/
+func randomValues(r *rand.Rand, values []string, max int) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	if max > len(values) {
+		max = len(values)
+	}
+
+	picked := make([]string, 0, max)
+	for _, i := range r.Perm(len(values))[:r.Intn(max)+1] {
+		picked = append(picked, values[i])
+	}
+	return picked
+}
+
+/*
+stringSet - This function will return a set of the values provided.
+*/
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
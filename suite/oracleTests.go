@@ -0,0 +1,108 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"math/rand"
+)
+
+/*
+TestOracleROCollection - This method will run randomized queries against the
+objects and manifest endpoints of the Read-Only collection and compare each
+result to the result computed by an in-memory model of the collection. The
+queries are generated from the seed so a failing query can be run again with
+the same seed.
+*/
+func (s *Suite) TestOracleROCollection() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Testing Randomized Queries Read-Only Collection")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	if s.Settings.Queries <= 0 {
+		s.Logger.Println("++ Skipping the randomized query tests, the number of queries is 0\n")
+		return
+	}
+
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/"
+
+	corpus, problems := s.loadCorpus(s.corpusFile())
+	records, p := s.getAllManifestRecords(path)
+	problems += p
+	if problems != 0 || len(records) == 0 {
+		s.Logger.Println("-- ERROR: Unable to load the corpus or the manifest, skipping the randomized query tests")
+		s.ProblemsFound += problems
+		if len(records) == 0 {
+			s.ProblemsFound++
+		}
+		s.printTestSummary()
+		return
+	}
+
+	oracle, missing := newCollectionOracle(corpus, records)
+	if len(missing) != 0 {
+		for _, v := range missing {
+			s.Logger.Println("-- ERROR: Object", v, "is in the corpus but is not in the read-only collection")
+		}
+		s.Logger.Println("-- ERROR: The corpus does not match the read-only collection, skipping the randomized query tests")
+		s.ProblemsFound += len(missing)
+		s.printTestSummary()
+		return
+	}
+
+	s.Logger.Println("## Start Randomized Query Tests for RO Collections\n")
+	s.Logger.Println("++ Using seed", s.Settings.Seed, "for", s.Settings.Queries, "queries")
+	r := rand.New(rand.NewSource(s.Settings.Seed))
+	for i := 1; i <= s.Settings.Queries; i++ {
+		q := oracle.randomQuery(r)
+		if r.Intn(2) == 0 {
+			s.setPath(path + "objects/")
+			s.testOracle(i, resourceEnvelope, q, oracle, records)
+		} else {
+			s.setPath(path + "manifest/")
+			s.testOracle(i, resourceManifest, q, oracle, records)
+		}
+	}
+}
+
+/*
+testOracle - This method will get every page of the resource at the current
+path using the query provided and compare it to the result from the oracle.
+For the objects endpoint the content of each object is also compared.
+*/
+func (s *Suite) testOracle(test int, kind string, q oracleQuery, oracle *collectionOracle, records []manifestRecord) {
+	name := "Objects"
+	if kind == resourceManifest {
+		name = "Manifest"
+	}
+	s.Logger.Printf("## Test OR-%02d: Test Random %s Query Using %s\n", test, name, q)
+	s.Logger.Infoln("++ This test will compare the result of a random query to the result computed from the corpus")
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	expected := oracle.query(q)
+
+	all, problems := s.walkResource(kind, q.params(), q.limit)
+	s.ProblemsFound += problems
+
+	if kind == resourceEnvelope {
+		objects := make([]interface{}, 0, len(expected))
+		for _, v := range expected {
+			objects = append(objects, v.object)
+		}
+		s.ProblemsFound += s.compareObjects(objects, all.Objects)
+	} else {
+		keys := make([]string, 0, len(expected))
+		for _, v := range expected {
+			keys = append(keys, v.key)
+		}
+		s.ProblemsFound += s.compareKeys(keys, fillUnversionedKeys(all.Keys, records))
+
+		s.Logger.Infoln("++ Number of records expected:", len(keys))
+		s.Logger.Infoln("++ Number of records returned:", len(all.Keys))
+	}
+
+	s.printTestSummary()
+}
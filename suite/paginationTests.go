@@ -0,0 +1,245 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"io/ioutil"
+	"net/url"
+	"strconv"
+)
+
+// paginationMaxPages is the number of pages that will be requested before a
+// paginated walk is stopped. This prevents a broken server from keeping the
+// tests in an endless loop.
+const paginationMaxPages = 1000
+
+/*
+TestPaginationROCollection - This method will perform the pagination tests
+against the objects, manifest, and versions endpoints of the Read-Only
+collection. Each test will walk the results using a small limit and make sure
+the pages add up to the unpaginated result.
+*/
+func (s *Suite) TestPaginationROCollection() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Testing Pagination Read-Only Collection")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	s.Logger.Println("## Start Pagination Tests for RO Collections\n")
+	// Test objects pagination using a limit of 1
+	// Test objects pagination using a limit of 2
+	// Test objects pagination using a limit of 4
+	// Test manifest pagination using a limit of 1
+	// Test manifest pagination using a limit of 2
+	// Test versions pagination using a limit of 1
+	// Test versions pagination using a limit of 2
+	allIndicators := GenerateIndicatorData()
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/"
+
+	s.setPath(path + "objects/")
+	s.testPagination01()
+	s.testPagination02()
+	s.testPagination03()
+
+	s.setPath(path + "manifest/")
+	s.testPagination04()
+	s.testPagination05()
+
+	s.setPath(path + "objects/" + allIndicators[0].ID + "/versions/")
+	s.testPagination06()
+	s.testPagination07()
+}
+
+/*
+testPagination01 - This method will walk every version of every object in the
+read-only collection one object at a time.
+*/
+func (s *Suite) testPagination01() {
+	s.Logger.Println("## Test PG-01: Test Objects Pagination Using Limit 1")
+	s.Logger.Infoln("++ This test will walk all versions of all objects in the read-only collection using a limit of 1")
+	s.testPaginationResponse(resourceEnvelope, 1)
+}
+
+/*
+testPagination02 - This method will walk every version of every object in the
+read-only collection two objects at a time.
+*/
+func (s *Suite) testPagination02() {
+	s.Logger.Println("## Test PG-02: Test Objects Pagination Using Limit 2")
+	s.Logger.Infoln("++ This test will walk all versions of all objects in the read-only collection using a limit of 2")
+	s.testPaginationResponse(resourceEnvelope, 2)
+}
+
+/*
+testPagination03 - This method will walk every version of every object in the
+read-only collection four objects at a time, so the last page is not full.
+*/
+func (s *Suite) testPagination03() {
+	s.Logger.Println("## Test PG-03: Test Objects Pagination Using Limit 4")
+	s.Logger.Infoln("++ This test will walk all versions of all objects in the read-only collection using a limit of 4")
+	s.testPaginationResponse(resourceEnvelope, 4)
+}
+
+/*
+testPagination04 - This method will walk the manifest of the read-only
+collection one record at a time.
+*/
+func (s *Suite) testPagination04() {
+	s.Logger.Println("## Test PG-04: Test Manifest Pagination Using Limit 1")
+	s.Logger.Infoln("++ This test will walk the manifest of the read-only collection using a limit of 1")
+	s.testPaginationResponse(resourceManifest, 1)
+}
+
+/*
+testPagination05 - This method will walk the manifest of the read-only
+collection two records at a time.
+*/
+func (s *Suite) testPagination05() {
+	s.Logger.Println("## Test PG-05: Test Manifest Pagination Using Limit 2")
+	s.Logger.Infoln("++ This test will walk the manifest of the read-only collection using a limit of 2")
+	s.testPaginationResponse(resourceManifest, 2)
+}
+
+/*
+testPagination06 - This method will walk the versions of a single indicator in
+the read-only collection one version at a time.
+*/
+func (s *Suite) testPagination06() {
+	s.Logger.Println("## Test PG-06: Test Versions Pagination Using Limit 1")
+	s.Logger.Infoln("++ This test will walk the versions of an indicator in the read-only collection using a limit of 1")
+	s.testPaginationResponse(resourceVersions, 1)
+}
+
+/*
+testPagination07 - This method will walk the versions of a single indicator in
+the read-only collection two versions at a time.
+*/
+func (s *Suite) testPagination07() {
+	s.Logger.Println("## Test PG-07: Test Versions Pagination Using Limit 2")
+	s.Logger.Infoln("++ This test will walk the versions of an indicator in the read-only collection using a limit of 2")
+	s.testPaginationResponse(resourceVersions, 2)
+}
+
+/*
+testPaginationResponse - This method is used by the pagination tests. It will
+get the full unpaginated result and then walk the same result using the limit
+provided and verify that the pages add up to the full result.
+*/
+func (s *Suite) testPaginationResponse(kind string, limit int) {
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	params := url.Values{}
+	if kind != resourceVersions {
+		params.Set("match[version]", "all")
+	}
+
+	expected, problems := s.walkPages(kind, params, 0)
+	if problems != 0 {
+		s.Logger.Println("-- ERROR: Unable to get the unpaginated result to compare against")
+		s.ProblemsFound += problems
+		s.printTestSummary()
+		return
+	}
+
+	actual, problems := s.walkPages(kind, params, limit)
+	s.ProblemsFound += problems
+	s.ProblemsFound += s.compareKeys(expected, actual)
+
+	s.Logger.Infoln("++ Number of records in unpaginated result:", len(expected))
+	s.Logger.Infoln("++ Number of records in paginated result:", len(actual))
+
+	s.printTestSummary()
+}
+
+/*
+walkPages - This method will request every page of the resource at the current
+path using the URL parameters provided and return the keys of all of the
+records in the order they were returned. A limit of 0 will not send the limit
+URL parameter. It will return an integer representing the number of problems
+found.
+*/
+func (s *Suite) walkPages(kind string, params url.Values, limit int) ([]string, int) {
+	all, problems := s.walkResource(kind, params, limit)
+	return all.Keys, problems
+}
+
+/*
+walkResource - This method will request every page of the resource at the
+current path using the URL parameters provided and return a single page that
+holds all of the keys, and for an envelope all of the objects, in the order
+they were returned. A limit of 0 will not send the limit URL parameter. The
+next URL parameter is used to get the next page when the server provides it,
+otherwise the value of the X-TAXII-Date-Added-Last header is sent as
+added_after. It will return an integer representing the number of problems
+found.
+*/
+func (s *Suite) walkResource(kind string, params url.Values, limit int) (resourcePage, int) {
+	var all resourcePage
+	problems := 0
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+
+	values := url.Values{}
+	for k, v := range params {
+		values[k] = v
+	}
+	if limit > 0 {
+		values.Set("limit", strconv.Itoa(limit))
+	}
+	s.Req.URL.RawQuery = values.Encode()
+
+	for pages := 1; ; pages++ {
+		s.Logger.Infoln("++ Query Params:", s.makePrettyQueryParams())
+
+		resp := s.doRequest()
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		s.handleError(err)
+
+		if p := s.checkResponseCode(resp.StatusCode, 200); p != 0 {
+			return all, problems + p
+		}
+
+		page, err := decodePage(kind, body)
+		if err != nil {
+			s.Logger.Println("-- ERROR: Invalid", kind, "resource returned", err)
+			return all, problems + 1
+		}
+
+		if limit > 0 && len(page.Keys) > limit {
+			s.Logger.Println("-- ERROR: Page", pages, "returned", len(page.Keys), "records but the limit was", limit)
+			problems++
+		}
+		all.Keys = append(all.Keys, page.Keys...)
+		all.Objects = append(all.Objects, page.Objects...)
+
+		if !page.More {
+			return all, problems
+		}
+
+		if len(page.Keys) == 0 {
+			s.Logger.Println("-- ERROR: Page", pages, "was empty but more was set to true")
+			return all, problems + 1
+		}
+
+		if pages >= paginationMaxPages {
+			s.Logger.Println("-- ERROR: More was still set to true after", pages, "pages")
+			return all, problems + 1
+		}
+
+		if page.Next != "" {
+			values.Set("next", page.Next)
+		} else if last := resp.Header.Get("X-TAXII-Date-Added-Last"); last != "" {
+			values.Set("added_after", last)
+		} else {
+			s.Logger.Println("-- ERROR: More was set to true but neither next nor the X-TAXII-Date-Added-Last header was returned")
+			return all, problems + 1
+		}
+		s.Req.URL.RawQuery = values.Encode()
+	}
+}
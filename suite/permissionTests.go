@@ -0,0 +1,160 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"net/http"
+)
+
+/*
+TestCollectionPermissions - This method will make sure the server enforces the
+can_read and can_write flags of the Read-Only, Write-Only, and Read-Write
+collections. The results are reported for each collection. The Write-Only and
+Read-Write tests are not applicable to a read-only implementation.
+*/
+func (s *Suite) TestCollectionPermissions() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Testing Collection Permissions")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	// Test GET objects from the write-only collection
+	// Test GET manifest from the write-only collection
+	// Test POST objects to the read-only collection
+	// Test GET objects from the read-write collection
+	// Test GET manifest from the read-write collection
+	// Test POST objects to the read-write collection
+	results := make(map[string]int)
+	tested := []string{"Read-Only"}
+
+	s.Logger.Println("## Start Permission Tests for WO Collections\n")
+	if !s.writeTestsNotApplicable() {
+		path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.WriteOnly + "/"
+		s.setPath(path + "objects/")
+		results["Write-Only"] += s.testPermission01()
+		s.setPath(path + "manifest/")
+		results["Write-Only"] += s.testPermission02()
+		tested = append(tested, "Write-Only")
+	}
+
+	s.Logger.Println("## Start Permission Tests for RO Collections\n")
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/"
+	s.setPath(path + "objects/")
+	results["Read-Only"] += s.testPermission03()
+
+	s.Logger.Println("## Start Permission Tests for RW Collections\n")
+	if !s.writeTestsNotApplicable() {
+		path = s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadWrite + "/"
+		s.setPath(path + "objects/")
+		results["Read-Write"] += s.testPermission04()
+		s.setPath(path + "manifest/")
+		results["Read-Write"] += s.testPermission05()
+		s.setPath(path + "objects/")
+		results["Read-Write"] += s.testPermission06()
+		tested = append(tested, "Read-Write")
+	}
+
+	s.Logger.Println("## Permission Results")
+	for _, v := range tested {
+		if results[v] == 0 {
+			s.Logger.Println("== SUCCESS:", v, "collection permissions are enforced")
+		} else {
+			s.Logger.Println("== FAILURE:", v, "collection permissions are not enforced,", results[v], "problems found")
+		}
+	}
+	s.Logger.Println("")
+}
+
+/*
+testPermission01 - This method will make sure objects can not be read from the
+write-only collection.
+*/
+func (s *Suite) testPermission01() int {
+	s.Logger.Println("## Test PE-01: Test Write-Only Collection Denies GET Objects")
+	s.Logger.Infoln("++ This test will GET the objects from the write-only collection and check to see if a 403 status code is returned")
+	return s.testPermissionResponse(http.MethodGet, 403)
+}
+
+/*
+testPermission02 - This method will make sure the manifest can not be read from
+the write-only collection.
+*/
+func (s *Suite) testPermission02() int {
+	s.Logger.Println("## Test PE-02: Test Write-Only Collection Denies GET Manifest")
+	s.Logger.Infoln("++ This test will GET the manifest from the write-only collection and check to see if a 403 status code is returned")
+	return s.testPermissionResponse(http.MethodGet, 403)
+}
+
+/*
+testPermission03 - This method will make sure objects can not be added to the
+read-only collection. A read-only implementation may not support POST at all,
+so in Configuration 1 a 405 status code is also accepted.
+*/
+func (s *Suite) testPermission03() int {
+	s.Logger.Println("## Test PE-03: Test Read-Only Collection Denies POST Objects")
+	if s.Configuration == ConfigurationReadOnly {
+		s.Logger.Infoln("++ This test will POST an envelope to the read-only collection and check to see if a 403 or 405 status code is returned")
+		return s.testPermissionResponse(http.MethodPost, 403, 405)
+	}
+	s.Logger.Infoln("++ This test will POST an envelope to the read-only collection and check to see if a 403 status code is returned")
+	return s.testPermissionResponse(http.MethodPost, 403)
+}
+
+/*
+testPermission04 - This method will make sure objects can be read from the
+read-write collection.
+*/
+func (s *Suite) testPermission04() int {
+	s.Logger.Println("## Test PE-04: Test Read-Write Collection Allows GET Objects")
+	s.Logger.Infoln("++ This test will GET the objects from the read-write collection and check to see if a 200 status code is returned")
+	return s.testPermissionResponse(http.MethodGet, 200)
+}
+
+/*
+testPermission05 - This method will make sure the manifest can be read from the
+read-write collection.
+*/
+func (s *Suite) testPermission05() int {
+	s.Logger.Println("## Test PE-05: Test Read-Write Collection Allows GET Manifest")
+	s.Logger.Infoln("++ This test will GET the manifest from the read-write collection and check to see if a 200 status code is returned")
+	return s.testPermissionResponse(http.MethodGet, 200)
+}
+
+/*
+testPermission06 - This method will make sure objects can be added to the
+read-write collection.
+*/
+func (s *Suite) testPermission06() int {
+	s.Logger.Println("## Test PE-06: Test Read-Write Collection Allows POST Objects")
+	s.Logger.Infoln("++ This test will POST an envelope to the read-write collection and check to see if a 202 status code is returned")
+	return s.testPermissionResponse(http.MethodPost, 202)
+}
+
+/*
+testPermissionResponse - This method is used by the permission tests. It will
+make a GET request or POST a new envelope to the current path and check the
+response code against the expected response codes. It will return an integer
+representing the number of problems found so the results can be reported for
+each collection.
+*/
+func (s *Suite) testPermissionResponse(method string, expected ...int) int {
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+	if method == http.MethodPost {
+		s.setBody(s.FullMediaType, s.newWriteEnvelope())
+	}
+
+	resp := s.doRequest()
+	defer resp.Body.Close()
+	s.ProblemsFound += s.checkResponseCode(resp.StatusCode, expected...)
+
+	problems := s.ProblemsFound
+	s.printTestSummary()
+	return problems
+}
@@ -0,0 +1,176 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+/*
+TestPostContentRWCollection - This method will perform the Content-Type and
+payload tests against the objects endpoint of the Read-Write collection. Each
+test will POST an envelope and make sure the server accepts only the TAXII
+media type and rejects malformed JSON.
+*/
+func (s *Suite) TestPostContentRWCollection() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Testing Post Content Read-Write Collection")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	if s.writeTestsNotApplicable() {
+		return
+	}
+
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadWrite + "/objects/"
+	s.setPath(path)
+
+	s.Logger.Println("## Start Post Content Tests for RW Collections\n")
+	// Test valid Content-Type
+	// Test missing Content-Type
+	// Test generic JSON Content-Type
+	// Test STIX 2.0 Content-Type
+	// Test malformed JSON
+	s.testPostContent01()
+	s.testPostContent02()
+	s.testPostContent03()
+	s.testPostContent04()
+	s.testPostContent05()
+}
+
+/*
+testPostContent01 - This method will POST an envelope using the TAXII 2.1
+media type and make sure it is accepted.
+*/
+func (s *Suite) testPostContent01() {
+	s.Logger.Println("## Test PC-01: Test Valid Content-Type")
+	s.Logger.Infoln("++ This test will POST an envelope using the", s.FullMediaType, "Content-Type and check to see if a 202 status code is returned")
+	s.testPostContentResponse(s.FullMediaType, s.newWriteEnvelope(), 202)
+}
+
+/*
+testPostContent02 - This method will POST an envelope without a Content-Type
+and make sure it is rejected.
+*/
+func (s *Suite) testPostContent02() {
+	s.Logger.Println("## Test PC-02: Test Missing Content-Type")
+	s.Logger.Infoln("++ This test will POST an envelope without a Content-Type and check to see if a 415 status code is returned")
+	s.testPostContentResponse("", s.newWriteEnvelope(), 415)
+}
+
+/*
+testPostContent03 - This method will POST an envelope using the generic JSON
+media type and make sure it is rejected.
+*/
+func (s *Suite) testPostContent03() {
+	s.Logger.Println("## Test PC-03: Test Generic JSON Content-Type")
+	s.Logger.Infoln("++ This test will POST an envelope using the application/json Content-Type and check to see if a 415 status code is returned")
+	s.testPostContentResponse("application/json", s.newWriteEnvelope(), 415)
+}
+
+/*
+testPostContent04 - This method will POST an envelope using the TAXII 2.0 STIX
+media type and make sure it is rejected.
+*/
+func (s *Suite) testPostContent04() {
+	s.Logger.Println("## Test PC-04: Test STIX 2.0 Content-Type")
+	s.Logger.Infoln("++ This test will POST an envelope using the application/vnd.oasis.stix+json; version=2.0 Content-Type and check to see if a 415 status code is returned")
+	s.testPostContentResponse("application/vnd.oasis.stix+json; version=2.0", s.newWriteEnvelope(), 415)
+}
+
+/*
+testPostContent05 - This method will POST malformed JSON using the TAXII 2.1
+media type and make sure it is rejected.
+*/
+func (s *Suite) testPostContent05() {
+	s.Logger.Println("## Test PC-05: Test Malformed JSON")
+	s.Logger.Infoln("++ This test will POST malformed JSON and check to see if a 400 or 422 status code is returned")
+	s.testPostContentResponse(s.FullMediaType, []byte(`{"objects": [{"type": "indicator",`), 400, 422)
+}
+
+/*
+testPostContentResponse - This method is used by the post content tests. It will
+POST the data provided using the content type provided and check the response
+code. When the data is accepted the status resource that is returned is also
+checked.
+*/
+func (s *Suite) testPostContentResponse(contentType string, data []byte, expected ...int) {
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+	s.setBody(contentType, data)
+
+	resp := s.doRequest()
+	defer resp.Body.Close()
+	s.ProblemsFound += s.checkResponseCode(resp.StatusCode, expected...)
+
+	if resp.StatusCode == 202 {
+		body, err := ioutil.ReadAll(resp.Body)
+		s.handleError(err)
+		s.ProblemsFound += s.checkContentType(resp.Header.Get("Content-type"), s.FullMediaType)
+		s.ProblemsFound += s.checkStatusResource(body, 1)
+	}
+
+	s.printTestSummary()
+}
+
+/*
+checkStatusResource - This method will verify that the body is a valid TAXII
+status resource for a request that contained the number of objects provided. It
+will return an integer representing the number of problems found.
+*/
+func (s *Suite) checkStatusResource(body []byte, total int) int {
+	problems := 0
+
+	var o statusResource
+	if err := json.Unmarshal(body, &o); err != nil {
+		s.Logger.Println("-- ERROR: Invalid status resource returned", err)
+		return 1
+	}
+
+	if o.ID == "" {
+		s.Logger.Println("-- ERROR: Status resource is missing the required id property")
+		problems++
+	}
+
+	if o.Status != "complete" && o.Status != "pending" {
+		s.Logger.Println("-- ERROR: Status resource status should be complete or pending. Got", o.Status)
+		problems++
+	}
+
+	if o.TotalCount != total {
+		s.Logger.Println("-- ERROR: Expected status resource total_count", total, "Got", o.TotalCount)
+		problems++
+	}
+
+	if o.SuccessCount+o.FailureCount+o.PendingCount != o.TotalCount {
+		s.Logger.Println("-- ERROR: Status resource success_count, failure_count, and pending_count do not add up to total_count")
+		problems++
+	}
+
+	if s.Debug {
+		data, _ := json.MarshalIndent(o, "", "    ")
+		s.Logger.Debugln("++ Status Resource Returned:\n", string(data))
+	}
+
+	return problems
+}
+
+/*
+newWriteEnvelope - This method will return an envelope containing a single new
+indicator that can be sent to a collection.
+*/
+func (s *Suite) newWriteEnvelope() []byte {
+	var e envelopeResource
+	e.Objects = append(e.Objects, GenerateWriteIndicator())
+
+	data, err := json.Marshal(e)
+	s.handleError(err)
+	return data
+}
@@ -0,0 +1,355 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+)
+
+/*
+Preflight - This method will check that the TAXII Server is ready to be tested
+before getContentTests or addContentTests are run. It checks connectivity, TLS,
+authentication, the presence and flags of the three TestLab collections, and
+that the read-only collection contains exactly the TestLab data. If
+FindCollections is set, the collection IDs are found before they are checked.
+The write-only and read-write collections are only required when the TAXII
+Server is a read and write implementation. Each problem is logged along with
+what can be done to fix it. It will return an integer representing the number
+of problems found.
+*/
+func (s *Suite) Preflight() int {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Running Pre-flight Checks")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	before := s.problemsTotal
+
+	// The rest of the checks can not run without a connection since every
+	// request that fails to connect is fatal.
+	if !s.preflightConnectivity() {
+		return s.problemsTotal - before
+	}
+	s.preflightTLS()
+	if s.preflightAuthentication() {
+		// The collections are found here so it only happens once the TAXII
+		// Server is known to be reachable.
+		if s.Settings.FindCollections {
+			s.FindCollectionIDs()
+		}
+		s.DetectConfiguration()
+		s.preflightCollections()
+		s.preflightROContent()
+	}
+
+	problems := s.problemsTotal - before
+	s.Logger.Println("## Pre-flight Results")
+	if problems == 0 {
+		s.Logger.Println("== SUCCESS: The TAXII Server is ready to be tested\n")
+	} else {
+		s.Logger.Println("== FAILURE:", problems, "problems need to be fixed before the TAXII Server can be tested\n")
+	}
+	return problems
+}
+
+/*
+preflightConnectivity - This method will make sure the TAXII Server can be
+reached at the configured URL. It will return false if it can not be reached.
+*/
+func (s *Suite) preflightConnectivity() bool {
+	s.Logger.Println("## Test PF-01: Test Connectivity")
+	s.setPath(s.Settings.Discovery)
+	s.Logger.Infoln("++ This test will check that the TAXII Server can be reached at", s.Req.URL.Host)
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+
+	resp, err := s.Client.Do(s.Req)
+	if err != nil {
+		s.Logger.Println("-- ERROR: Unable to connect to the TAXII Server", err)
+		if e, ok := err.(net.Error); ok && e.Timeout() {
+			s.Logger.Println("-- FIX: The connection timed out, make sure the host and port in --url are correct and that no firewall is blocking them")
+		} else {
+			s.Logger.Println("-- FIX: Make sure the TAXII Server is running and that --url, and --proxy if one is needed, are correct")
+		}
+		s.ProblemsFound++
+		s.printTestSummary()
+		return false
+	}
+	resp.Body.Close()
+
+	s.Logger.Infoln("++ Connected to", s.Req.URL.Host, "and got HTTP response code", resp.StatusCode)
+	s.printTestSummary()
+	return true
+}
+
+/*
+preflightTLS - This method will make sure the TAXII Server is using HTTPS. The
+test suite does not verify certificates, so a certificate that can not be
+verified is only reported.
+*/
+func (s *Suite) preflightTLS() {
+	s.Logger.Println("## Test PF-02: Test TLS")
+	s.Logger.Infoln("++ This test will check that the TAXII Server is using HTTPS with a valid certificate")
+
+	if s.Req.URL.Scheme != "https" {
+		s.Logger.Println("-- ERROR: The TAXII Server URL is not using HTTPS, which TAXII requires")
+		s.Logger.Println("-- FIX: Enable TLS on the TAXII Server and use an https:// address with --url")
+		s.ProblemsFound++
+		s.printTestSummary()
+		return
+	}
+
+	// A proxy would hide the certificate of the TAXII Server
+	if s.Settings.Proxy != "" {
+		s.Logger.Println("++ Skipping the certificate check since a proxy is being used\n")
+		return
+	}
+
+	host := s.Req.URL.Host
+	if s.Req.URL.Port() == "" {
+		host = net.JoinHostPort(s.Req.URL.Hostname(), "443")
+	}
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", host, &tls.Config{ServerName: s.Req.URL.Hostname()})
+	if err != nil {
+		s.Logger.Println("++ The certificate could not be verified:", err)
+		s.Logger.Println("++ The tests will still run, but TAXII clients that verify certificates will not be able to connect")
+	} else {
+		state := conn.ConnectionState()
+		if len(state.PeerCertificates) > 0 {
+			s.Logger.Infoln("++ Certificate for", state.PeerCertificates[0].Subject.CommonName, "is valid until", state.PeerCertificates[0].NotAfter)
+		}
+		conn.Close()
+	}
+
+	s.printTestSummary()
+}
+
+/*
+preflightAuthentication - This method will make sure the configured username
+and password are accepted by the API root. It will return false if they are not.
+*/
+func (s *Suite) preflightAuthentication() bool {
+	s.Logger.Println("## Test PF-03: Test Authentication")
+	s.setPath(s.Settings.APIRoot)
+	s.Logger.Infoln("++ This test will check that the username and password are accepted by the API root")
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+
+	resp := s.doRequest()
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		s.Logger.Infoln("++ The API root accepted the credentials")
+	case http.StatusUnauthorized, http.StatusForbidden:
+		s.Logger.Println("-- ERROR: The API root rejected the credentials with HTTP response code", resp.StatusCode)
+		if s.Settings.Username == "" {
+			s.Logger.Println("-- FIX: The TAXII Server requires authentication, provide a username and password with -n and -p")
+		} else {
+			s.Logger.Println("-- FIX: Check the username and password given with -n and -p and make sure the user can access the API root")
+		}
+		s.ProblemsFound++
+	case http.StatusNotFound:
+		s.Logger.Println("-- ERROR: The API root", s.Req.URL.Path, "was not found")
+		s.Logger.Println("-- FIX: Make sure the API root given with -a is configured on the TAXII Server")
+		s.ProblemsFound++
+	default:
+		s.Logger.Println("-- ERROR: The API root returned HTTP response code", resp.StatusCode)
+		s.Logger.Println("-- FIX: Check the logs of the TAXII Server for the cause of the error")
+		s.ProblemsFound++
+	}
+
+	ok := s.ProblemsFound == 0
+	s.printTestSummary()
+	return ok
+}
+
+/*
+preflightCollections - This method will make sure the read-only, write-only,
+and read-write collections exist and have the right can_read and can_write
+flags.
+*/
+func (s *Suite) preflightCollections() {
+	s.Logger.Println("## Test PF-04: Test TestLab Collections")
+	s.Logger.Infoln("++ This test will check that the TestLab collections exist and have the right permissions")
+
+	o, problems := s.getCollectionsResource()
+	if problems != 0 || o == nil {
+		s.Logger.Println("-- ERROR: Unable to get the collections resource")
+		s.Logger.Println("-- FIX: Make sure the collections endpoint is working by running basicTests")
+		s.ProblemsFound += problems
+		s.printTestSummary()
+		return
+	}
+
+	roles := []struct {
+		name     string
+		flag     string
+		id       string
+		canRead  bool
+		canWrite bool
+	}{
+		{"read-only", "-r", s.CollectionIDs.ReadOnly, true, false},
+		{"write-only", "-w", s.CollectionIDs.WriteOnly, false, true},
+		{"read-write", "-z", s.CollectionIDs.ReadWrite, true, true},
+	}
+
+	for _, r := range roles {
+		if r.canWrite && s.Configuration == ConfigurationReadOnly {
+			s.Logger.Infoln("++ The", r.name, "collection is not applicable to", s.configurationName())
+			continue
+		}
+
+		found := false
+		for _, c := range o.Collections {
+			if c.ID != r.id {
+				continue
+			}
+			found = true
+			if c.CanRead != r.canRead || c.CanWrite != r.canWrite {
+				s.Logger.Println("-- ERROR: The", r.name, "collection", c.ID, "has can_read", c.CanRead, "and can_write", c.CanWrite)
+				s.Logger.Println("-- FIX: Set can_read to", r.canRead, "and can_write to", r.canWrite, "on this collection for the test user")
+				s.ProblemsFound++
+			} else {
+				s.Logger.Infoln("++ Found the", r.name, "collection", c.ID)
+			}
+		}
+
+		if !found {
+			s.Logger.Println("-- ERROR: The", r.name, "collection", r.id, "was not found")
+			s.Logger.Println("-- FIX: Create the collection as shown in the README, give its ID with", r.flag, "or use --findcollections")
+			s.ProblemsFound++
+		}
+	}
+
+	s.printTestSummary()
+}
+
+/*
+preflightROContent - This method will make sure the read-only collection
+contains exactly the objects and versions from data/indicators.json, the full
+corpus from testData, or the corpus file if one was given.
+*/
+func (s *Suite) preflightROContent() {
+	s.Logger.Println("## Test PF-05: Test Read-Only Collection Content")
+	s.Logger.Infoln("++ This test will check that the read-only collection only contains the TestLab data")
+
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/"
+	records, problems := s.getAllManifestRecords(path)
+	if problems != 0 {
+		s.Logger.Println("-- ERROR: Unable to get the manifest of the read-only collection")
+		s.Logger.Println("-- FIX: Make sure the read-only collection exists and that the test user can read it")
+		s.ProblemsFound += problems
+		s.printTestSummary()
+		return
+	}
+
+	// Either data/indicators.json or the full corpus from testData can be
+	// loaded in to the read-only collection, unless a corpus file was given.
+	var keys []string
+	if s.Settings.CorpusFile != "" {
+		corpus, problems := s.loadCorpus(s.Settings.CorpusFile)
+		if problems != 0 {
+			s.Logger.Println("-- FIX: Give the STIX bundle that was loaded in to the read-only collection with --corpusfile")
+			s.ProblemsFound += problems
+			s.printTestSummary()
+			return
+		}
+		for _, v := range corpus {
+			keys = append(keys, objectKey(v))
+		}
+	} else {
+		fullCorpus := containsFixtureObjects(records)
+		if fullCorpus {
+			s.Logger.Infoln("++ The full corpus from testData is loaded in the read-only collection")
+		}
+		keys = fixtureKeys(fullCorpus)
+	}
+
+	expected := make(map[string]bool)
+	for _, v := range fillUnversionedKeys(keys, records) {
+		expected[v] = true
+	}
+
+	actual := make(map[string]bool)
+	var missing, extra []string
+	for _, v := range records {
+		k := versionKey(v.ID, v.Version)
+		actual[k] = true
+		if !expected[k] {
+			extra = append(extra, k)
+		}
+	}
+	for k := range expected {
+		if !actual[k] {
+			missing = append(missing, k)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+
+	for _, v := range missing {
+		s.Logger.Println("-- ERROR: Record", v, "is missing from the read-only collection")
+	}
+	for _, v := range extra {
+		s.Logger.Println("-- ERROR: Record", v, "is in the read-only collection but is not part of the TestLab data")
+	}
+
+	if len(missing) != 0 {
+		s.Logger.Println("-- FIX: Load data/indicators.json, or the full corpus with testData, in to the read-only collection")
+	}
+	if len(extra) != 0 {
+		s.Logger.Println("-- FIX: Empty the read-only collection and load only data/indicators.json, or the full corpus with testData, in to it")
+	}
+	s.ProblemsFound += len(missing) + len(extra)
+
+	s.Logger.Infoln("++ Number of records expected:", len(expected))
+	s.Logger.Infoln("++ Number of records found:", len(records))
+	s.printTestSummary()
+}
+
+/*
+fixtureKeys - This function will return the keys that identify every version
+of every object that is in data/indicators.json, and the rest of the objects in
+the full corpus if it is loaded.
+*/
+func fixtureKeys(fullCorpus bool) []string {
+	var keys []string
+	if fullCorpus {
+		for _, v := range otherFixtureObjects() {
+			keys = append(keys, objectKey(v))
+		}
+	}
+	for _, v := range GenerateIndicatorData() {
+		keys = append(keys, versionKey(v.ID, v.Modified))
+	}
+	for _, v := range GenerateSpecVersionData() {
+		keys = append(keys, versionKey(v.ID, v.Modified))
+	}
+	return keys
+}
+
+/*
+versionKey - This function will return a key for a single version of an object
+that does not depend on how many sub-second digits the timestamp was written
+with.
+*/
+func versionKey(id, version string) string {
+	if t, err := time.Parse(time.RFC3339Nano, version); err == nil {
+		version = t.UTC().Format(time.RFC3339Nano)
+	}
+	return id + " version " + version
+}
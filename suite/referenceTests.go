@@ -0,0 +1,239 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+/*
+TestReferencesROCollection - This method will retrieve the identity, marking
+definitions, relationships, and sighting from the Read-Only collection and make
+sure they are correct. It will then make sure every reference in every object
+in the collection, like created_by_ref, object_marking_refs, source_ref, and
+sighting_of_ref, points to an object that is in the collection. These tests
+need the full corpus from testData to be loaded.
+*/
+func (s *Suite) TestReferencesROCollection() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Testing References Read-Only Collection")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	if !s.fullCorpusLoaded() {
+		s.Logger.Println("++ Skipping the reference tests, only data/indicators.json is loaded in the read-only collection\n")
+		return
+	}
+
+	s.Logger.Println("## Start Reference Tests for RO Collections\n")
+	// Test getting the identity by ID
+	// Test getting the marking definitions by type
+	// Test getting the relationships by type
+	// Test getting the sighting by type
+	// Test that every reference resolves within the collection
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/objects/"
+
+	identities := GenerateIdentityData()
+	s.setPath(path + identities[0].ID + "/")
+	s.testReference01(identities[0])
+
+	s.setPath(path)
+	s.testReference02()
+	s.testReference03()
+	s.testReference04()
+	s.testReference05()
+}
+
+/*
+testReference01 - This method will get the TestLab identity by its ID.
+*/
+func (s *Suite) testReference01(o interface{}) {
+	s.Logger.Println("## Test RF-01: Test Get Identity By ID")
+	s.Logger.Infoln("++ This test will get the identity that is referenced by the created_by_ref property of the TestLab objects")
+	s.testReferenceResponse(nil, []interface{}{o})
+}
+
+/*
+testReference02 - This method will get the TLP marking definitions by type.
+*/
+func (s *Suite) testReference02() {
+	s.Logger.Println("## Test RF-02: Test Get Marking Definitions")
+	s.Logger.Infoln("++ This test will filter the read-only collection by type using Marking Definition")
+
+	var expected []interface{}
+	for _, v := range GenerateMarkingDefinitionData() {
+		expected = append(expected, v)
+	}
+	s.testReferenceResponse(referenceTypeFilter("marking-definition"), expected)
+}
+
+/*
+testReference03 - This method will get the relationships by type.
+*/
+func (s *Suite) testReference03() {
+	s.Logger.Println("## Test RF-03: Test Get Relationships")
+	s.Logger.Infoln("++ This test will filter the read-only collection by type using Relationship")
+
+	var expected []interface{}
+	for _, v := range GenerateRelationshipData() {
+		expected = append(expected, v)
+	}
+	s.testReferenceResponse(referenceTypeFilter("relationship"), expected)
+}
+
+/*
+testReference04 - This method will get the sighting by type.
+*/
+func (s *Suite) testReference04() {
+	s.Logger.Println("## Test RF-04: Test Get Sightings")
+	s.Logger.Infoln("++ This test will filter the read-only collection by type using Sighting")
+
+	var expected []interface{}
+	for _, v := range GenerateSightingData() {
+		expected = append(expected, v)
+	}
+	s.testReferenceResponse(referenceTypeFilter("sighting"), expected)
+}
+
+/*
+testReference05 - This method will get every version of every object in the
+read-only collection and make sure every reference points to an object that is
+also in the collection.
+*/
+func (s *Suite) testReference05() {
+	s.Logger.Println("## Test RF-05: Test References Resolve Within The Collection")
+	s.Logger.Infoln("++ This test will check that every reference in the read-only collection points to an object in the collection")
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	params := url.Values{}
+	params.Set("match[version]", "all")
+	params.Set("match[spec_version]", "2.0,2.1")
+
+	all, problems := s.walkResource(resourceEnvelope, params, 0)
+	s.ProblemsFound += problems
+
+	found := make(map[string]bool)
+	objects := make(map[string]map[string]interface{})
+	var keys []string
+	for _, v := range all.Objects {
+		var o map[string]interface{}
+		if err := json.Unmarshal(v, &o); err != nil {
+			s.Logger.Println("-- ERROR: Object could not be decoded", err)
+			s.ProblemsFound++
+			continue
+		}
+		id, _ := o["id"].(string)
+		found[id] = true
+
+		var h stixObjectHeader
+		json.Unmarshal(v, &h)
+		objects[h.key()] = o
+		keys = append(keys, h.key())
+	}
+
+	count := 0
+	for _, k := range keys {
+		for _, r := range findReferences("", objects[k]) {
+			count++
+			if !found[r.id] {
+				s.Logger.Println("-- ERROR: Object", k, "property", r.property, "references", r.id, "which is not in the read-only collection")
+				s.ProblemsFound++
+			}
+		}
+	}
+
+	s.Logger.Infoln("++ Number of objects checked:", len(keys))
+	s.Logger.Infoln("++ Number of references checked:", count)
+
+	s.printTestSummary()
+}
+
+/*
+testReferenceResponse - This method is used by the reference tests. It will get
+every version of the objects at the current path using the filter provided and
+make sure the expected objects are returned.
+*/
+func (s *Suite) testReferenceResponse(filter url.Values, expected []interface{}) {
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	params := url.Values{}
+	params.Set("match[version]", "all")
+	for k, v := range filter {
+		params[k] = v
+	}
+
+	all, problems := s.walkResource(resourceEnvelope, params, 0)
+	s.ProblemsFound += problems
+	s.ProblemsFound += s.compareObjects(expected, all.Objects)
+
+	s.printTestSummary()
+}
+
+/*
+stixReference - This type holds a single reference that was found in a STIX
+object and the property it was found in.
+*/
+type stixReference struct {
+	property string
+	id       string
+}
+
+/*
+findReferences - This function will find every property in a decoded STIX
+object, including nested properties like granular_markings, whose name ends in
+_ref or _refs and return the IDs they reference.
+*/
+func findReferences(prefix string, o map[string]interface{}) []stixReference {
+	var refs []stixReference
+
+	names := make([]string, 0, len(o))
+	for k := range o {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		property := k
+		if prefix != "" {
+			property = prefix + "." + k
+		}
+
+		switch v := o[k].(type) {
+		case string:
+			if strings.HasSuffix(k, "_ref") {
+				refs = append(refs, stixReference{property, v})
+			}
+		case []interface{}:
+			for _, item := range v {
+				switch i := item.(type) {
+				case string:
+					if strings.HasSuffix(k, "_refs") {
+						refs = append(refs, stixReference{property, i})
+					}
+				case map[string]interface{}:
+					refs = append(refs, findReferences(property, i)...)
+				}
+			}
+		case map[string]interface{}:
+			refs = append(refs, findReferences(property, v)...)
+		}
+	}
+
+	return refs
+}
+
+/*
+referenceTypeFilter - This function will return the URL parameters that filter
+by the type provided.
+*/
+func referenceTypeFilter(objectType string) url.Values {
+	params := url.Values{}
+	params.Set("match[type]", objectType)
+	return params
+}
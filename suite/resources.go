@@ -0,0 +1,187 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/freetaxii/libstix2/resources/envelope"
+)
+
+// These constants identify the three TAXII resources that can be returned in
+// pages from a collection.
+const (
+	resourceEnvelope = "envelope"
+	resourceManifest = "manifest"
+	resourceVersions = "versions"
+)
+
+/*
+envelopeResource - This type is used to build a TAXII envelope that is sent to
+a collection
+*/
+type envelopeResource struct {
+	More    bool          `json:"more,omitempty"`
+	Next    string        `json:"next,omitempty"`
+	Objects []interface{} `json:"objects,omitempty"`
+}
+
+/*
+manifestResource - This type is used to decode a TAXII manifest resource
+*/
+type manifestResource struct {
+	More    bool             `json:"more,omitempty"`
+	Objects []manifestRecord `json:"objects,omitempty"`
+}
+
+/*
+manifestRecord - This type is used to decode a single record from a TAXII
+manifest resource
+*/
+type manifestRecord struct {
+	ID        string `json:"id"`
+	DateAdded string `json:"date_added"`
+	Version   string `json:"version"`
+	MediaType string `json:"media_type,omitempty"`
+}
+
+/*
+versionsResource - This type is used to decode a TAXII versions resource
+*/
+type versionsResource struct {
+	More     bool     `json:"more,omitempty"`
+	Versions []string `json:"versions,omitempty"`
+}
+
+/*
+statusResource - This type is used to decode a TAXII status resource
+*/
+type statusResource struct {
+	ID               string          `json:"id"`
+	Status           string          `json:"status"`
+	RequestTimestamp string          `json:"request_timestamp,omitempty"`
+	TotalCount       int             `json:"total_count"`
+	SuccessCount     int             `json:"success_count"`
+	Successes        []statusDetails `json:"successes,omitempty"`
+	FailureCount     int             `json:"failure_count"`
+	Failures         []statusDetails `json:"failures,omitempty"`
+	PendingCount     int             `json:"pending_count"`
+	Pendings         []statusDetails `json:"pendings,omitempty"`
+}
+
+/*
+statusDetails - This type is used to decode a single status details entry from
+a TAXII status resource
+*/
+type statusDetails struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+	Message string `json:"message,omitempty"`
+}
+
+/*
+errorMessage - This type is used to decode a TAXII error message resource
+*/
+type errorMessage struct {
+	Title           string                 `json:"title"`
+	Description     string                 `json:"description,omitempty"`
+	ErrorID         string                 `json:"error_id,omitempty"`
+	ErrorCode       string                 `json:"error_code,omitempty"`
+	HTTPStatus      string                 `json:"http_status,omitempty"`
+	ExternalDetails string                 `json:"external_details,omitempty"`
+	Details         map[string]interface{} `json:"details,omitempty"`
+}
+
+/*
+stixObjectHeader - This type is used to decode just the properties that
+identify a single version of any STIX object, regardless of its type.
+*/
+type stixObjectHeader struct {
+	ObjectType  string `json:"type"`
+	SpecVersion string `json:"spec_version,omitempty"`
+	ID          string `json:"id"`
+	Created     string `json:"created,omitempty"`
+	Modified    string `json:"modified,omitempty"`
+}
+
+/*
+version - This method will return the version of the object, which is the
+modified timestamp, or the created timestamp for objects like marking
+definitions that do not have a modified timestamp.
+*/
+func (h stixObjectHeader) version() string {
+	if h.Modified != "" {
+		return h.Modified
+	}
+	return h.Created
+}
+
+/*
+key - This method will return a string that uniquely identifies this version
+of the object.
+*/
+func (h stixObjectHeader) key() string {
+	return h.ID + " version " + h.version()
+}
+
+/*
+resourcePage - This type holds the parts of a single page of results that are
+needed to walk a paginated response.
+*/
+type resourcePage struct {
+	More    bool
+	Next    string
+	Keys    []string
+	Objects []json.RawMessage
+}
+
+/*
+decodePage - This function will decode a single page of a TAXII envelope,
+manifest, or versions resource and return the keys of the records it contains.
+*/
+func decodePage(kind string, body []byte) (resourcePage, error) {
+	var p resourcePage
+
+	switch kind {
+	case resourceEnvelope:
+		e, err := envelope.DecodeRaw(bytes.NewReader(body))
+		if err != nil {
+			return p, err
+		}
+		p.More = e.More
+		p.Next = e.Next
+		p.Objects = e.Objects
+		for _, v := range e.Objects {
+			var h stixObjectHeader
+			if err := json.Unmarshal(v, &h); err != nil {
+				return p, err
+			}
+			p.Keys = append(p.Keys, h.key())
+		}
+
+	case resourceManifest:
+		var m manifestResource
+		if err := json.Unmarshal(body, &m); err != nil {
+			return p, err
+		}
+		p.More = m.More
+		for _, v := range m.Objects {
+			p.Keys = append(p.Keys, v.ID+" version "+v.Version)
+		}
+
+	case resourceVersions:
+		var v versionsResource
+		if err := json.Unmarshal(body, &v); err != nil {
+			return p, err
+		}
+		p.More = v.More
+		p.Keys = append(p.Keys, v.Versions...)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,338 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// These constants control how long the status of each posted envelope is
+// polled for when the TAXII Server does not finish adding the objects right
+// away.
+const (
+	seedPollInterval = 1 * time.Second
+	seedPollTimeout  = 5 * time.Minute
+)
+
+/*
+Seeder - This type will load objects in to a collection on any TAXII 2.1
+Server by posting envelopes to the objects endpoint of the collection. Objects
+are added one at a time and the key of each one is kept so the collection can
+be verified once every envelope is posted.
+*/
+type Seeder struct {
+	s            *Suite
+	collectionID string
+	path         string
+	batchSize    int
+	maxLength    int
+	batch        [][]byte
+	batchBytes   int
+	batches      int
+	keys         []string
+	envelopes    []int
+	pending      []string
+	before       int
+}
+
+/*
+NewSeeder - This method will create a seeder for the collection provided. Each
+envelope will hold at most batchSize objects and will not be larger than the
+max_content_length of the API Root.
+*/
+func (s *Suite) NewSeeder(collectionID string, batchSize int) *Seeder {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Seeding Collection", collectionID)
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	sd := &Seeder{
+		s:            s,
+		collectionID: collectionID,
+		path:         s.Settings.APIRoot + "collections/" + collectionID + "/objects/",
+		batchSize:    batchSize,
+		before:       s.problemsTotal,
+	}
+
+	s.Logger.Println("## Test SD-01: Post Objects")
+	s.Logger.Infoln("++ This test will post the objects to the collection in envelopes of up to", batchSize, "objects")
+
+	root, problems := s.getAPIRootResource()
+	if problems != 0 || root == nil {
+		s.Logger.Println("-- ERROR: Unable to get the max_content_length from the API Root, the size of each envelope will not be limited")
+		s.ProblemsFound += problems
+	} else {
+		sd.maxLength = root.MaxContentLength
+		s.Logger.Infoln("++ API Root max_content_length:", sd.maxLength)
+	}
+
+	return sd
+}
+
+/*
+Add - This method will add a single version of a STIX object to the current
+envelope and post the envelope once it is full.
+*/
+func (sd *Seeder) Add(o interface{}) {
+	data, err := json.Marshal(o)
+	sd.s.handleError(err)
+	key := objectKey(o)
+
+	// An envelope is {"objects":[ and ]} around the objects, which are
+	// separated by commas.
+	if sd.maxLength > 0 && len(data)+14 > sd.maxLength {
+		sd.s.Logger.Println("-- ERROR: Object", key, "is", len(data), "bytes and can not fit in an envelope under the max_content_length")
+		sd.s.ProblemsFound++
+		return
+	}
+
+	if len(sd.batch) != 0 && (len(sd.batch) >= sd.batchSize || (sd.maxLength > 0 && sd.batchBytes+len(data)+15 > sd.maxLength)) {
+		sd.post()
+	}
+
+	sd.batch = append(sd.batch, data)
+	sd.batchBytes += len(data)
+	if len(sd.batch) > 1 {
+		sd.batchBytes++
+	}
+	sd.keys = append(sd.keys, key)
+	sd.envelopes = append(sd.envelopes, sd.batches+1)
+}
+
+/*
+Close - This method will post the last envelope, wait for the TAXII Server to
+finish adding the objects, and read the collection back to make sure every
+object was added. It will return an integer representing the number of
+problems found.
+*/
+func (sd *Seeder) Close() int {
+	s := sd.s
+
+	if len(sd.batch) != 0 {
+		sd.post()
+	}
+	s.Logger.Infoln("++ Posted", len(sd.keys), "objects in", sd.batches, "envelopes")
+	s.printTestSummary()
+
+	sd.waitForStatus()
+	sd.verify()
+
+	problems := s.problemsTotal - sd.before
+	s.Logger.Println("## Seeding Results")
+	if problems == 0 {
+		s.Logger.Println("== SUCCESS: The collection contains every object\n")
+	} else {
+		s.Logger.Println("== FAILURE:", problems, "problems found while seeding the collection\n")
+	}
+	return problems
+}
+
+/*
+post - This method will post the current envelope to the objects endpoint of
+the collection and check the status resource that is returned.
+*/
+func (sd *Seeder) post() {
+	s := sd.s
+	sd.batches++
+
+	var envelope bytes.Buffer
+	envelope.WriteString(`{"objects":[`)
+	envelope.Write(bytes.Join(sd.batch, []byte(",")))
+	envelope.WriteString(`]}`)
+
+	s.setPath(sd.path)
+	s.startTest()
+	s.setAccept(s.FullMediaType)
+	s.enableAuth(s.Settings.Username, s.Settings.Password)
+	s.setBody(s.FullMediaType, envelope.Bytes())
+
+	s.Logger.Infoln("++ Posting envelope", sd.batches, "with", len(sd.batch), "objects and", envelope.Len(), "bytes")
+	resp := s.doRequest()
+	defer resp.Body.Close()
+
+	if p := s.checkResponseCode(resp.StatusCode, http.StatusAccepted); p != 0 {
+		switch resp.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			s.Logger.Println("-- FIX: The user given with -n and -p needs to be able to write to the collection to seed it")
+		case http.StatusRequestEntityTooLarge:
+			s.Logger.Println("-- FIX: Use a smaller --batch so each envelope is under the max_content_length")
+		}
+		s.ProblemsFound += p
+		sd.resetBatch()
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	s.handleError(err)
+	if p := s.checkStatusResource(body, len(sd.batch)); p != 0 {
+		s.ProblemsFound += p
+		sd.resetBatch()
+		return
+	}
+
+	var o statusResource
+	json.Unmarshal(body, &o)
+	if o.Status == "pending" {
+		sd.pending = append(sd.pending, o.ID)
+	} else {
+		s.ProblemsFound += sd.reportFailures(o)
+	}
+	sd.resetBatch()
+}
+
+/*
+resetBatch - This method will empty the current envelope.
+*/
+func (sd *Seeder) resetBatch() {
+	sd.batch = sd.batch[:0]
+	sd.batchBytes = 0
+}
+
+/*
+waitForStatus - This method will poll the status endpoint for every envelope
+that the TAXII Server had not finished adding when it was posted, until it is
+complete or the timeout is reached.
+*/
+func (sd *Seeder) waitForStatus() {
+	s := sd.s
+	s.Logger.Println("## Test SD-02: Wait For Status")
+	s.Logger.Infoln("++ This test will poll the status of the", len(sd.pending), "envelopes that were still pending")
+
+	deadline := time.Now().Add(seedPollTimeout)
+	for _, id := range sd.pending {
+		s.setPath(s.Settings.APIRoot + "status/" + id + "/")
+		for {
+			s.startTest()
+			s.setAccept(s.FullMediaType)
+			s.enableAuth(s.Settings.Username, s.Settings.Password)
+
+			resp := s.doRequest()
+			body, err := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			s.handleError(err)
+
+			if p := s.checkResponseCode(resp.StatusCode, http.StatusOK); p != 0 {
+				s.Logger.Println("-- ERROR: Unable to get status", id)
+				s.ProblemsFound += p
+				break
+			}
+
+			var o statusResource
+			if err := json.Unmarshal(body, &o); err != nil {
+				s.Logger.Println("-- ERROR: Invalid status resource returned", err)
+				s.ProblemsFound++
+				break
+			}
+
+			if o.Status == "complete" {
+				s.Logger.Infoln("++ Status", id, "is complete")
+				s.ProblemsFound += sd.reportFailures(o)
+				break
+			}
+
+			if time.Now().After(deadline) {
+				s.Logger.Println("-- ERROR: Status", id, "was still pending after", seedPollTimeout, "with", o.PendingCount, "objects pending")
+				s.ProblemsFound++
+				break
+			}
+			time.Sleep(seedPollInterval)
+		}
+	}
+
+	s.printTestSummary()
+}
+
+/*
+reportFailures - This method will log each object in the status resource that
+the TAXII Server did not add. It will return an integer representing the
+number of problems found.
+*/
+func (sd *Seeder) reportFailures(o statusResource) int {
+	for _, v := range o.Failures {
+		sd.s.Logger.Println("-- ERROR: Object", v.ID, "version", v.Version, "was not added:", v.Message)
+	}
+	if len(o.Failures) == 0 && o.FailureCount != 0 {
+		sd.s.Logger.Println("-- ERROR: Status", o.ID, "has", o.FailureCount, "objects that were not added")
+	}
+	return o.FailureCount
+}
+
+/*
+verify - This method will read the manifest of the collection back and make
+sure every version of every object that was posted is in it. The date added
+values also need to increase in the order the objects were posted, except for
+objects that were posted in the same envelope.
+*/
+func (sd *Seeder) verify() {
+	s := sd.s
+	s.Logger.Println("## Test SD-03: Verify Collection Content")
+	s.Logger.Infoln("++ This test will read the manifest of the collection to make sure every object was added in the order it was posted")
+
+	records, problems := s.getAllManifestRecords(s.Settings.APIRoot + "collections/" + sd.collectionID + "/")
+	if problems != 0 {
+		s.Logger.Println("-- ERROR: Unable to read the manifest of the collection")
+		s.Logger.Println("-- FIX: The user given with -n and -p needs to be able to read the collection to verify it")
+		s.ProblemsFound += problems
+		s.printTestSummary()
+		return
+	}
+
+	found := make(map[string]time.Time)
+	for _, v := range records {
+		found[versionKey(v.ID, v.Version)], _ = time.Parse(time.RFC3339Nano, v.DateAdded)
+	}
+
+	missing, unordered := 0, 0
+	posted := make(map[string]bool)
+	var previous, latest time.Time
+	envelope := 0
+	for i, k := range fillUnversionedKeys(sd.keys, records) {
+		if posted[k] {
+			continue
+		}
+		posted[k] = true
+		added, ok := found[k]
+		if !ok {
+			s.Logger.Println("-- ERROR: Record", k, "was posted but is not in the collection")
+			missing++
+			continue
+		}
+
+		// Only the objects in the envelopes posted before this one need to
+		// have been added before it.
+		if sd.envelopes[i] != envelope {
+			envelope = sd.envelopes[i]
+			previous = latest
+		}
+		if !added.After(previous) {
+			s.Logger.Println("-- ERROR: Record", k, "has a date added value of", added.Format(time.RFC3339Nano), "which is not after the objects posted before it")
+			unordered++
+		}
+		if added.After(latest) {
+			latest = added
+		}
+	}
+	if unordered != 0 {
+		s.Logger.Println("-- FIX: The date added value needs to be the time the TAXII Server added each object")
+	}
+	s.ProblemsFound += missing + unordered
+
+	if extra := len(found) - len(posted) + missing; extra > 0 {
+		s.Logger.Println("++ The collection also contains", extra, "records that were not posted, the read-only collection needs to be empty before it is seeded")
+	}
+
+	s.Logger.Infoln("++ Number of records posted:", len(posted))
+	s.Logger.Infoln("++ Number of records found:", len(records))
+	s.printTestSummary()
+}
@@ -0,0 +1,217 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+TestSortOrderROCollection - This method will perform the sort order tests
+against the objects, manifest, and versions endpoints of the Read-Only
+collection. TAXII requires records to be returned in ascending date added
+order, so the date added value of each record is read from the manifest and
+the records returned by each endpoint, in one page and across pages, are
+checked against it.
+*/
+func (s *Suite) TestSortOrderROCollection() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Testing Sort Order Read-Only Collection")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	s.Logger.Println("## Start Sort Order Tests for RO Collections\n")
+	// Test the sort order of the objects endpoint
+	// Test the sort order of the objects endpoint across pages
+	// Test the sort order of the manifest endpoint
+	// Test the sort order of the manifest endpoint across pages
+	// Test the sort order of the versions endpoint
+	// Test the sort order of the versions endpoint across pages
+	// Test the sort order of objects of different types
+	allIndicators := GenerateIndicatorData()
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/"
+
+	records, problems := s.getManifestRecords(path)
+	if problems != 0 || len(records) == 0 {
+		s.Logger.Println("-- ERROR: Unable to get the date added values from the manifest, skipping the sort order tests")
+		s.ProblemsFound += problems
+		if len(records) == 0 {
+			s.ProblemsFound++
+		}
+		s.printTestSummary()
+		return
+	}
+
+	dateAdded := make(map[string]string)
+	for _, v := range records {
+		dateAdded[versionKey(v.ID, v.Version)] = v.DateAdded
+		// Objects without a modified or created timestamp, like STIX
+		// Cyber-observable Objects, only have one version and are returned
+		// without one.
+		dateAdded[versionKey(v.ID, "")] = v.DateAdded
+	}
+
+	s.setPath(path + "objects/")
+	s.testSortOrder01(dateAdded)
+	s.testSortOrder02(dateAdded)
+
+	s.setPath(path + "manifest/")
+	s.testSortOrder03(dateAdded)
+	s.testSortOrder04(dateAdded)
+
+	s.setPath(path + "objects/" + allIndicators[0].ID + "/versions/")
+	s.testSortOrder05(allIndicators[0].ID, dateAdded)
+	s.testSortOrder06(allIndicators[0].ID, dateAdded)
+
+	s.setPath(path + "objects/")
+	s.testSortOrder07(records, dateAdded)
+}
+
+/*
+testSortOrder01 - This method will make sure every version of every object in
+the read-only collection is returned in ascending date added order.
+*/
+func (s *Suite) testSortOrder01(dateAdded map[string]string) {
+	s.Logger.Println("## Test SO-01: Test Objects Sort Order")
+	s.Logger.Infoln("++ This test will check to see if the objects in the read-only collection are returned in ascending date added order")
+	s.testSortOrderResponse(resourceEnvelope, "", nil, 0, dateAdded)
+}
+
+/*
+testSortOrder02 - This method will make sure every version of every object in
+the read-only collection is returned in ascending date added order when the
+result is split across pages.
+*/
+func (s *Suite) testSortOrder02(dateAdded map[string]string) {
+	s.Logger.Println("## Test SO-02: Test Objects Sort Order Across Pages")
+	s.Logger.Infoln("++ This test will check to see if the objects in the read-only collection are returned in ascending date added order using a limit of 2")
+	s.testSortOrderResponse(resourceEnvelope, "", nil, 2, dateAdded)
+}
+
+/*
+testSortOrder03 - This method will make sure the manifest of the read-only
+collection is returned in ascending date added order.
+*/
+func (s *Suite) testSortOrder03(dateAdded map[string]string) {
+	s.Logger.Println("## Test SO-03: Test Manifest Sort Order")
+	s.Logger.Infoln("++ This test will check to see if the manifest of the read-only collection is returned in ascending date added order")
+	s.testSortOrderResponse(resourceManifest, "", nil, 0, dateAdded)
+}
+
+/*
+testSortOrder04 - This method will make sure the manifest of the read-only
+collection is returned in ascending date added order when the result is split
+across pages.
+*/
+func (s *Suite) testSortOrder04(dateAdded map[string]string) {
+	s.Logger.Println("## Test SO-04: Test Manifest Sort Order Across Pages")
+	s.Logger.Infoln("++ This test will check to see if the manifest of the read-only collection is returned in ascending date added order using a limit of 2")
+	s.testSortOrderResponse(resourceManifest, "", nil, 2, dateAdded)
+}
+
+/*
+testSortOrder05 - This method will make sure the versions of an indicator are
+returned in ascending date added order.
+*/
+func (s *Suite) testSortOrder05(id string, dateAdded map[string]string) {
+	s.Logger.Println("## Test SO-05: Test Versions Sort Order")
+	s.Logger.Infoln("++ This test will check to see if the versions of an indicator are returned in ascending date added order")
+	s.testSortOrderResponse(resourceVersions, id, nil, 0, dateAdded)
+}
+
+/*
+testSortOrder06 - This method will make sure the versions of an indicator are
+returned in ascending date added order when the result is split across pages.
+*/
+func (s *Suite) testSortOrder06(id string, dateAdded map[string]string) {
+	s.Logger.Println("## Test SO-06: Test Versions Sort Order Across Pages")
+	s.Logger.Infoln("++ This test will check to see if the versions of an indicator are returned in ascending date added order using a limit of 2")
+	s.testSortOrderResponse(resourceVersions, id, nil, 2, dateAdded)
+}
+
+/*
+testSortOrder07 - This method will request every type of object in the
+read-only collection by type and make sure the objects are returned in
+ascending date added order and not grouped by type.
+*/
+func (s *Suite) testSortOrder07(records []manifestRecord, dateAdded map[string]string) {
+	s.Logger.Println("## Test SO-07: Test Objects Sort Order Across Types")
+
+	found := make(map[string]bool)
+	var types []string
+	for _, v := range records {
+		t := strings.SplitN(v.ID, "--", 2)[0]
+		if !found[t] {
+			found[t] = true
+			types = append(types, t)
+		}
+	}
+	sort.Strings(types)
+
+	if len(types) < 2 {
+		s.Logger.Println("++ Skipping this test, the read-only collection only contains one type of object\n")
+		return
+	}
+	s.Logger.Infoln("++ This test will check to see if objects of the types", strings.Join(types, ","), "are returned in ascending date added order")
+
+	params := url.Values{}
+	params.Set("match[type]", strings.Join(types, ","))
+	s.testSortOrderResponse(resourceEnvelope, "", params, 2, dateAdded)
+}
+
+/*
+testSortOrderResponse - This method is used by the sort order tests. It will
+walk every version of the records at the current path using the limit provided
+and make sure the date added value of each record is not before the date added
+value of the record that came before it. The ID is only needed for the versions
+endpoint since the versions resource does not include it.
+*/
+func (s *Suite) testSortOrderResponse(kind, id string, filter url.Values, limit int, dateAdded map[string]string) {
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	params := url.Values{}
+	if kind != resourceVersions {
+		params.Set("match[version]", "all")
+	}
+	for k, v := range filter {
+		params[k] = v
+	}
+
+	keys, problems := s.walkPages(kind, params, limit)
+	s.ProblemsFound += problems
+
+	var previous time.Time
+	var previousKey string
+	for _, v := range keys {
+		if kind == resourceVersions {
+			v = id + " version " + v
+		}
+		parts := strings.SplitN(v, " version ", 2)
+		key := versionKey(parts[0], parts[len(parts)-1])
+
+		added, found := dateAdded[key]
+		if !found {
+			s.Logger.Println("-- ERROR: Record", v, "was returned but is not in the manifest")
+			s.ProblemsFound++
+			continue
+		}
+
+		t, _ := time.Parse(time.RFC3339Nano, added)
+		if previousKey != "" && t.Before(previous) {
+			s.Logger.Println("-- ERROR: Record", v, "added", added, "was returned after record", previousKey, "added", previous.Format(time.RFC3339Nano))
+			s.ProblemsFound++
+		}
+		previous = t
+		previousKey = v
+	}
+
+	s.Logger.Infoln("++ Number of records returned:", len(keys))
+
+	s.printTestSummary()
+}
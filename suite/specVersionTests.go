@@ -0,0 +1,148 @@
+// Copyright 2018 Bret Jordan, All rights reserved.
+//
+// Use of this source code is governed by an Apache 2.0 license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package suite
+
+import (
+	"net/url"
+
+	"github.com/freetaxii/libstix2/objects/indicator"
+)
+
+/*
+TestSpecVersionROCollection - This method will perform the match[spec_version]
+filtering tests against the objects, object, manifest, and versions endpoints
+of the Read-Only collection. These tests use the spec version data, which has
+an indicator with both a STIX 2.0 and a STIX 2.1 version and an indicator that
+only has a STIX 2.0 version.
+*/
+func (s *Suite) TestSpecVersionROCollection() {
+	s.Logger.Println("## ---------------------------------------------------------")
+	s.Logger.Println("## Testing Spec Version Read-Only Collection")
+	s.Logger.Println("## ---------------------------------------------------------")
+
+	s.Logger.Println("## Start Spec Version Filtering Tests for RO Collections\n")
+	// Test the default spec version on each endpoint
+	// Test spec version filtering using 2.0 on each endpoint
+	// Test spec version filtering using 2.1 on each endpoint
+	// Test spec version filtering using 2.0,2.1 on each endpoint
+	specIndicators := GenerateSpecVersionData()
+	i30 := specIndicators[0]
+	i31 := specIndicators[1]
+	i40 := specIndicators[2]
+	path := s.Settings.APIRoot + "collections/" + s.CollectionIDs.ReadOnly + "/"
+
+	// The objects and manifest tests are limited to the spec version data
+	// so that the rest of the data in the collection does not matter.
+	ids := i30.ID + "," + i40.ID
+
+	s.setPath(path + "objects/")
+	s.testSpecVersion01(resourceEnvelope, ids, specVersionKeys(i31, i40))
+	s.testSpecVersion02(resourceEnvelope, ids, specVersionKeys(i30, i40))
+	s.testSpecVersion03(resourceEnvelope, ids, specVersionKeys(i31))
+	s.testSpecVersion04(resourceEnvelope, ids, specVersionKeys(i30, i31, i40))
+
+	s.setPath(path + "objects/" + i30.ID + "/")
+	s.testSpecVersion01(resourceEnvelope, "", specVersionKeys(i31))
+	s.testSpecVersion02(resourceEnvelope, "", specVersionKeys(i30))
+	s.testSpecVersion03(resourceEnvelope, "", specVersionKeys(i31))
+	s.testSpecVersion04(resourceEnvelope, "", specVersionKeys(i30, i31))
+
+	s.setPath(path + "manifest/")
+	s.testSpecVersion01(resourceManifest, ids, specVersionKeys(i31, i40))
+	s.testSpecVersion02(resourceManifest, ids, specVersionKeys(i30, i40))
+	s.testSpecVersion03(resourceManifest, ids, specVersionKeys(i31))
+	s.testSpecVersion04(resourceManifest, ids, specVersionKeys(i30, i31, i40))
+
+	s.setPath(path + "objects/" + i30.ID + "/versions/")
+	s.testSpecVersion01(resourceVersions, "", []string{i31.Modified})
+	s.testSpecVersion02(resourceVersions, "", []string{i30.Modified})
+	s.testSpecVersion03(resourceVersions, "", []string{i31.Modified})
+	s.testSpecVersion04(resourceVersions, "", []string{i30.Modified, i31.Modified})
+}
+
+/*
+testSpecVersion01 - This method will make sure that only the latest spec
+version of each object is returned when match[spec_version] is not used.
+*/
+func (s *Suite) testSpecVersion01(kind, ids string, expected []string) {
+	s.Logger.Println("## Test SV-01: Test Default Spec Version")
+	s.Logger.Infoln("++ This test will not use the spec version filter and should only get the latest spec version of each object")
+	s.testSpecVersionResponse(kind, ids, "", expected)
+}
+
+/*
+testSpecVersion02 - This method will make sure that only the STIX 2.0 versions
+are returned.
+*/
+func (s *Suite) testSpecVersion02(kind, ids string, expected []string) {
+	s.Logger.Println("## Test SV-02: Test Spec Version Filtering Using 2.0")
+	s.Logger.Infoln("++ This test will filter by spec version using 2.0")
+	s.testSpecVersionResponse(kind, ids, "2.0", expected)
+}
+
+/*
+testSpecVersion03 - This method will make sure that only the STIX 2.1 versions
+are returned.
+*/
+func (s *Suite) testSpecVersion03(kind, ids string, expected []string) {
+	s.Logger.Println("## Test SV-03: Test Spec Version Filtering Using 2.1")
+	s.Logger.Infoln("++ This test will filter by spec version using 2.1")
+	s.testSpecVersionResponse(kind, ids, "2.1", expected)
+}
+
+/*
+testSpecVersion04 - This method will make sure that both the STIX 2.0 and STIX
+2.1 versions are returned.
+*/
+func (s *Suite) testSpecVersion04(kind, ids string, expected []string) {
+	s.Logger.Println("## Test SV-04: Test Spec Version Filtering Using 2.0,2.1")
+	s.Logger.Infoln("++ This test will filter by spec version using 2.0 and 2.1")
+	s.testSpecVersionResponse(kind, ids, "2.0,2.1", expected)
+}
+
+/*
+testSpecVersionResponse - This method is used by the spec version tests. It
+will request every version of the objects, limited to the IDs provided, using
+the spec version filter provided and make sure only the expected records are
+returned. An empty spec version will not send the match[spec_version] URL
+parameter.
+*/
+func (s *Suite) testSpecVersionResponse(kind, ids, specVersion string, expected []string) {
+	s.Logger.Infoln("++ Calling Path:", s.Req.URL.Path)
+
+	params := url.Values{}
+	if kind != resourceVersions {
+		params.Set("match[version]", "all")
+	}
+	if ids != "" {
+		params.Set("match[id]", ids)
+	}
+	if specVersion != "" {
+		params.Set("match[spec_version]", specVersion)
+	}
+
+	actual, problems := s.walkPages(kind, params, 0)
+	s.ProblemsFound += problems
+	s.ProblemsFound += s.compareKeys(expected, actual)
+
+	s.Logger.Infoln("++ Number of records expected:", len(expected))
+	s.Logger.Infoln("++ Number of records returned:", len(actual))
+
+	s.printTestSummary()
+}
+
+/*
+specVersionKeys - This function will return the keys that identify each version
+of the indicators provided.
+*/
+func specVersionKeys(indicators ...indicator.Indicator) []string {
+	var keys []string
+	for _, v := range indicators {
+		keys = append(keys, v.ID+" version "+v.Modified)
+	}
+	return keys
+}
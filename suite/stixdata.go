@@ -7,12 +7,41 @@
 package suite
 
 import (
+	"strings"
+
 	"github.com/freetaxii/libstix2/objects/attackpattern"
 	"github.com/freetaxii/libstix2/objects/campaign"
+	"github.com/freetaxii/libstix2/objects/identity"
 	"github.com/freetaxii/libstix2/objects/indicator"
+	"github.com/freetaxii/libstix2/objects/relationship"
+	"github.com/freetaxii/libstix2/objects/sighting"
 	"github.com/freetaxii/libstix2/objects/threatactor"
 )
 
+// These constants are the IDs of the TLP marking definitions that are defined
+// in the STIX specification.
+const (
+	TLPWhite = "marking-definition--613f2e26-407d-48c7-9eca-b8e91df99dc9"
+	TLPGreen = "marking-definition--34098fce-860f-48ae-8e50-ebd3cc5e41da"
+	TLPAmber = "marking-definition--f88d31f6-486f-44da-b317-01333bde0b82"
+	TLPRed   = "marking-definition--5e57c739-391a-4eb3-b6be-7d15ca92d5ed"
+)
+
+/*
+MarkingDefinition - This type holds a STIX marking definition. The TLP marking
+definitions are fixed by the STIX specification, so only the properties they
+use are defined.
+*/
+type MarkingDefinition struct {
+	ObjectType     string            `json:"type"`
+	SpecVersion    string            `json:"spec_version"`
+	ID             string            `json:"id"`
+	Created        string            `json:"created"`
+	DefinitionType string            `json:"definition_type"`
+	Name           string            `json:"name"`
+	Definition     map[string]string `json:"definition"`
+}
+
 func GenerateIndicatorData() []indicator.Indicator {
 	var indicators []indicator.Indicator
 
@@ -83,6 +112,58 @@ func GenerateIndicatorData() []indicator.Indicator {
 	return indicators
 }
 
+/*
+GenerateSpecVersionData - This function will generate indicators that have
+versions in both STIX 2.0 and STIX 2.1 form so that the match[spec_version]
+filter can be tested. Indicator 3 has a STIX 2.0 version followed by a STIX 2.1
+version and indicator 4 only has a STIX 2.0 version.
+*/
+func GenerateSpecVersionData() []indicator.Indicator {
+	var indicators []indicator.Indicator
+
+	i3 := indicator.New()
+	i3.SetID("indicator--6b2a7c7d-6c2b-4f58-9a2e-5b0d1a0c3e71")
+	i3.SetCreated("2018-08-08T06:51:06.123Z")
+	i3.SetModified("2018-08-08T06:51:06.123Z")
+	i3.SetCreatedByRef("identity--abd090f7-5ada-4506-b6d0-5feae5ff90bc")
+	i3.SetName("TestLab Indicator 3")
+	i3.SetDescription("This is indicator 3 for Read-Only TestLab Collection")
+	i3.AddLabel("malicious-activity")
+	i3.SetValidFrom("2018-08-08T06:51:06.123Z")
+	pattern3 := "[ domain-name:value = 'testlab3.example.com' ]"
+	i3.SetPattern(pattern3)
+	// STIX 2.0 objects do not have a spec_version property
+	i3.SpecVersion = ""
+	indicators = append(indicators, *i3)
+
+	// The STIX 2.1 version is made from a copy so the STIX 2.0 version is not
+	// changed, and it needs the pattern_type that STIX 2.1 requires.
+	i31 := *i3
+	i31.SetModified("2018-08-08T06:52:06.234Z")
+	i31.SpecVersion = "2.1"
+	i31.Labels = nil
+	i31.AddType("malicious-activity")
+	i31.SetPatternType("stix")
+	indicators = append(indicators, i31)
+
+	i4 := indicator.New()
+	i4.SetID("indicator--8f1d2c43-2e5a-4d8e-a1f3-7c9e0b6d5a24")
+	i4.SetCreated("2018-08-08T07:51:07.123Z")
+	i4.SetModified("2018-08-08T07:51:07.123Z")
+	i4.SetCreatedByRef("identity--abd090f7-5ada-4506-b6d0-5feae5ff90bc")
+	i4.SetName("TestLab Indicator 4")
+	i4.SetDescription("This is indicator 4 for Read-Only TestLab Collection")
+	i4.AddLabel("malicious-activity")
+	i4.SetValidFrom("2018-08-08T07:51:07.123Z")
+	pattern4 := "[ domain-name:value = 'testlab4.example.com' ]"
+	i4.SetPattern(pattern4)
+	// STIX 2.0 objects do not have a spec_version property
+	i4.SpecVersion = ""
+	indicators = append(indicators, *i4)
+
+	return indicators
+}
+
 func GenerateAttackPatternData() []attackpattern.AttackPattern {
 	var ap []attackpattern.AttackPattern
 
@@ -129,3 +210,329 @@ func GenerateCampaignData() []campaign.Campaign {
 
 	return c
 }
+
+/*
+STIXObject - This type holds any STIX object as decoded JSON. It is used for
+objects that libstix2 does not have a type for and for objects with extensions
+and custom properties that need to be sent and compared exactly.
+*/
+type STIXObject map[string]interface{}
+
+/*
+GenerateIdentityData - This function will generate the identity that created
+the TestLab objects. It is referenced by the created_by_ref property of the
+indicators.
+*/
+func GenerateIdentityData() []identity.Identity {
+	var identities []identity.Identity
+
+	id1 := identity.New()
+	id1.SetID("identity--abd090f7-5ada-4506-b6d0-5feae5ff90bc")
+	id1.SetCreated("2018-08-08T00:51:00.123Z")
+	id1.SetModified("2018-08-08T00:51:00.123Z")
+	id1.SetName("FreeTAXII TestLab")
+	id1.SetDescription("This is the identity that created the objects in the Read-Only TestLab Collection")
+	id1.SetIdentityClass("organization")
+	id1.AddSector("technology")
+	id1.SetContactInformation("https://github.com/freetaxii/testlab")
+	id1.AddObjectMarkingRef(TLPWhite)
+	identities = append(identities, *id1)
+
+	return identities
+}
+
+/*
+GenerateMarkingDefinitionData - This function will generate the four TLP
+marking definitions from the STIX specification.
+*/
+func GenerateMarkingDefinitionData() []MarkingDefinition {
+	var markings []MarkingDefinition
+
+	tlp := []struct {
+		id    string
+		level string
+	}{
+		{TLPWhite, "white"},
+		{TLPGreen, "green"},
+		{TLPAmber, "amber"},
+		{TLPRed, "red"},
+	}
+
+	for _, v := range tlp {
+		m := MarkingDefinition{
+			ObjectType:     "marking-definition",
+			SpecVersion:    "2.1",
+			ID:             v.id,
+			Created:        "2017-01-20T00:00:00.000Z",
+			DefinitionType: "tlp",
+			Name:           "TLP:" + strings.ToUpper(v.level),
+			Definition:     map[string]string{"tlp": v.level},
+		}
+		markings = append(markings, m)
+	}
+
+	return markings
+}
+
+/*
+GenerateRelationshipData - This function will generate two relationships. The
+first says indicator 1 indicates campaign 1 and the second says campaign 1 is
+attributed to threat actor 1.
+*/
+func GenerateRelationshipData() []relationship.Relationship {
+	var relationships []relationship.Relationship
+
+	r1 := relationship.New()
+	r1.SetID("relationship--6f3c59d5-0e2a-4b8d-9c1e-2a7f5b8e4d10")
+	r1.SetCreated("2018-08-08T08:51:08.123Z")
+	r1.SetModified("2018-08-08T08:51:08.123Z")
+	r1.SetCreatedByRef("identity--abd090f7-5ada-4506-b6d0-5feae5ff90bc")
+	r1.SetRelationshipType("indicates")
+	r1.SetDescription("TestLab Indicator 1 indicates TestLab Campaign 1")
+	r1.SetSourceRef("indicator--1efc6673-9d95-46c3-a09c-c29f926da9af")
+	r1.SetTargetRef("campaign--bba8b6c6-fa62-4767-8303-58390db33a19")
+	r1.AddObjectMarkingRef(TLPGreen)
+	relationships = append(relationships, *r1)
+
+	r2 := relationship.New()
+	r2.SetID("relationship--c2d8a1e4-7b3f-4e6a-8d5c-9f1b0a2e3c47")
+	r2.SetCreated("2018-08-08T08:52:08.234Z")
+	r2.SetModified("2018-08-08T08:52:08.234Z")
+	r2.SetCreatedByRef("identity--abd090f7-5ada-4506-b6d0-5feae5ff90bc")
+	r2.SetRelationshipType("attributed-to")
+	r2.SetDescription("TestLab Campaign 1 is attributed to TestLab Threat Actor 1")
+	r2.SetSourceRef("campaign--bba8b6c6-fa62-4767-8303-58390db33a19")
+	r2.SetTargetRef("threat-actor--a6036137-f757-482e-bf63-fcb5e25efdd8")
+	r2.AddObjectMarkingRef(TLPAmber)
+	relationships = append(relationships, *r2)
+
+	return relationships
+}
+
+/*
+GenerateSightingData - This function will generate a sighting of indicator 2
+by the TestLab identity.
+*/
+func GenerateSightingData() []sighting.Sighting {
+	var sightings []sighting.Sighting
+
+	s1 := sighting.New()
+	s1.SetID("sighting--4e0f7b2a-8c5d-4a1e-b3f6-1d9c2e7a5b80")
+	s1.SetCreated("2018-08-08T09:51:09.123Z")
+	s1.SetModified("2018-08-08T09:51:09.123Z")
+	s1.SetCreatedByRef("identity--abd090f7-5ada-4506-b6d0-5feae5ff90bc")
+	s1.SetDescription("TestLab Indicator 2 was seen by the TestLab")
+	s1.SetFirstSeen("2018-08-08T09:00:00.000Z")
+	s1.SetLastSeen("2018-08-08T09:30:00.000Z")
+	s1.SetCount(3)
+	s1.SetSightingOfRef("indicator--213dea46-8750-4b8b-b988-aae8f86a62d6")
+	s1.AddWhereSightedRef("identity--abd090f7-5ada-4506-b6d0-5feae5ff90bc")
+	s1.AddObjectMarkingRef(TLPGreen)
+	sightings = append(sightings, *s1)
+
+	return sightings
+}
+
+/*
+GenerateExtensionData - This function will generate an extension definition
+and a malware object that uses it. The malware object also has custom x_
+properties so it can be used to make sure the server does not drop properties
+it does not know about.
+*/
+func GenerateExtensionData() []STIXObject {
+	var data []STIXObject
+
+	e1 := STIXObject{
+		"type":            "extension-definition",
+		"spec_version":    "2.1",
+		"id":              "extension-definition--5a8c1e3f-2d7b-4f9a-b6e0-3c1d9f4a7b28",
+		"created_by_ref":  "identity--abd090f7-5ada-4506-b6d0-5feae5ff90bc",
+		"created":         "2018-08-08T10:51:10.123Z",
+		"modified":        "2018-08-08T10:51:10.123Z",
+		"name":            "TestLab Extension",
+		"description":     "This is the extension definition for the TestLab custom extension",
+		"schema":          "https://github.com/freetaxii/testlab",
+		"version":         "1.0.0",
+		"extension_types": []interface{}{"property-extension"},
+	}
+	data = append(data, e1)
+
+	m1 := STIXObject{
+		"type":           "malware",
+		"spec_version":   "2.1",
+		"id":             "malware--0c7b5b88-8ff7-4a4d-aa9d-feb398cd0061",
+		"created_by_ref": "identity--abd090f7-5ada-4506-b6d0-5feae5ff90bc",
+		"created":        "2018-08-08T10:52:10.234Z",
+		"modified":       "2018-08-08T10:52:10.234Z",
+		"name":           "TestLab Malware 1",
+		"description":    "This is malware 1 for Read-Only TestLab Collection",
+		"malware_types":  []interface{}{"remote-access-trojan"},
+		"is_family":      false,
+		"extensions": map[string]interface{}{
+			"extension-definition--5a8c1e3f-2d7b-4f9a-b6e0-3c1d9f4a7b28": map[string]interface{}{
+				"extension_type":  "property-extension",
+				"x_testlab_rank":  3,
+				"x_testlab_notes": []interface{}{"first seen in the TestLab", "second note"},
+			},
+		},
+		"x_testlab_score":   75,
+		"x_testlab_sources": []interface{}{"testlab-feed-1", "testlab-feed-2"},
+		"x_testlab_details": map[string]interface{}{
+			"analyst":  "TestLab",
+			"reviewed": true,
+		},
+	}
+	data = append(data, m1)
+
+	return data
+}
+
+/*
+GenerateObservableData - This function will generate STIX Cyber-observable
+Objects. The file and network traffic objects use predefined extensions, the
+file also uses the TestLab custom extension, and both have custom x_
+properties. Each ID is the deterministic UUIDv5 ID that STIX 2.1 requires,
+made from the ID contributing properties of the object in the STIX namespace
+00abedb4-aa42-466c-9c01-fed23315a9b7, so the IDs need to be made again if
+those properties are changed.
+*/
+func GenerateObservableData() []STIXObject {
+	var data []STIXObject
+
+	ip1 := STIXObject{
+		"type":         "ipv4-addr",
+		"spec_version": "2.1",
+		"id":           "ipv4-addr--6e7355af-8fc4-5257-a235-1ecf327b2763",
+		"value":        "192.168.100.100",
+	}
+	data = append(data, ip1)
+
+	ip2 := STIXObject{
+		"type":         "ipv4-addr",
+		"spec_version": "2.1",
+		"id":           "ipv4-addr--8e1117b8-79e6-50e1-b0d9-dd29fa545d69",
+		"value":        "192.168.200.200",
+	}
+	data = append(data, ip2)
+
+	d1 := STIXObject{
+		"type":                "domain-name",
+		"spec_version":        "2.1",
+		"id":                  "domain-name--cbba0ecc-eafd-5f2e-a264-fe1d1785fd00",
+		"value":               "testlab3.example.com",
+		"resolves_to_refs":    []interface{}{"ipv4-addr--6e7355af-8fc4-5257-a235-1ecf327b2763"},
+		"object_marking_refs": []interface{}{TLPWhite},
+	}
+	data = append(data, d1)
+
+	f1 := STIXObject{
+		"type":         "file",
+		"spec_version": "2.1",
+		"id":           "file--f26e7d6f-aa7c-53dc-b574-47e426e6a7fe",
+		"name":         "testlab.exe",
+		"size":         25536,
+		"hashes": map[string]interface{}{
+			"MD5":     "3773a88f65a5e780c8dff9cdc3a056f3",
+			"SHA-1":   "4d7d6b5fd4d2b0e9c2d5bb8f3e4a6c2d7b0f1e9a",
+			"SHA-256": "5e2bc7c1b3b3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3",
+		},
+		"extensions": map[string]interface{}{
+			"windows-pebinary-ext": map[string]interface{}{
+				"pe_type":            "exe",
+				"number_of_sections": 4,
+			},
+			"extension-definition--5a8c1e3f-2d7b-4f9a-b6e0-3c1d9f4a7b28": map[string]interface{}{
+				"extension_type":  "property-extension",
+				"x_testlab_rank":  1,
+				"x_testlab_notes": []interface{}{"downloaded from testlab3.example.com"},
+			},
+		},
+		"x_testlab_tag": "fidelity",
+	}
+	data = append(data, f1)
+
+	n1 := STIXObject{
+		"type":         "network-traffic",
+		"spec_version": "2.1",
+		"id":           "network-traffic--a8ed3d7d-a288-5445-9dfe-337ee000f366",
+		"src_ref":      "ipv4-addr--8e1117b8-79e6-50e1-b0d9-dd29fa545d69",
+		"dst_ref":      "ipv4-addr--6e7355af-8fc4-5257-a235-1ecf327b2763",
+		"dst_port":     80,
+		"protocols":    []interface{}{"ipv4", "tcp", "http"},
+		"extensions": map[string]interface{}{
+			"http-request-ext": map[string]interface{}{
+				"request_method":  "get",
+				"request_value":   "/download/testlab.exe",
+				"request_version": "http/1.1",
+				"request_header": map[string]interface{}{
+					"Host":       "testlab3.example.com",
+					"User-Agent": "TestLab",
+				},
+			},
+		},
+		"x_testlab_session": map[string]interface{}{
+			"id":   1,
+			"tags": []interface{}{"download", "testlab"},
+		},
+	}
+	data = append(data, n1)
+
+	return data
+}
+
+/*
+otherFixtureObjects - This function will return the objects in the full
+TestLab corpus that are not indicators, in the order testData adds them to the
+read-only collection.
+*/
+func otherFixtureObjects() []interface{} {
+	var objects []interface{}
+	for _, v := range GenerateAttackPatternData() {
+		objects = append(objects, v)
+	}
+	for _, v := range GenerateThreatActorData() {
+		objects = append(objects, v)
+	}
+	for _, v := range GenerateCampaignData() {
+		objects = append(objects, v)
+	}
+	for _, v := range GenerateIdentityData() {
+		objects = append(objects, v)
+	}
+	for _, v := range GenerateMarkingDefinitionData() {
+		objects = append(objects, v)
+	}
+	for _, v := range GenerateRelationshipData() {
+		objects = append(objects, v)
+	}
+	for _, v := range GenerateSightingData() {
+		objects = append(objects, v)
+	}
+	for _, v := range GenerateExtensionData() {
+		objects = append(objects, v)
+	}
+	for _, v := range GenerateObservableData() {
+		objects = append(objects, v)
+	}
+	return objects
+}
+
+/*
+GenerateWriteIndicator - This function will generate a new indicator with a
+random ID and the current time so it can be added to the write-only and
+read-write collections more than once.
+*/
+func GenerateWriteIndicator() *indicator.Indicator {
+	now := timestampNow()
+
+	i := indicator.New()
+	i.SetID("indicator--" + newUUID())
+	i.SetCreated(now)
+	i.SetModified(now)
+	i.SetName("TestLab Write Indicator")
+	i.SetDescription("This is an indicator that was added by the TestLab")
+	i.AddType("malicious-activity")
+	i.SetValidFrom(now)
+	i.SetPattern("[ ipv4-addr:value = '192.168.255.255' ]")
+	return i
+}
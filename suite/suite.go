@@ -11,7 +11,6 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/gologme/log"
@@ -27,19 +26,31 @@ type Suite struct {
 	TAXIIMediaType string
 	TAXIIVersion   string
 	FullMediaType  string
+	Configuration  int
 	Settings       struct {
-		Username  string
-		Password  string
-		URL       string
-		Proxy     string
-		Discovery string
-		APIRoot   string
+		Username            string
+		Password            string
+		URL                 string
+		Proxy               string
+		Discovery           string
+		APIRoot             string
+		ExpectedDiscovery   string
+		Credentials         string
+		FindCollections     bool
+		ExpectedCollections string
+		DataDir             string
+		CorpusFile          string
+		TestCasesFile       string
+		Seed                int64
+		Queries             int
 	}
 	CollectionIDs struct {
 		ReadOnly  string
 		WriteOnly string
 		ReadWrite string
 	}
+	problemsTotal int
+	dateAdded     map[string]time.Time
 }
 
 /*
@@ -86,19 +97,8 @@ func (s *Suite) Setup() {
 	// ------------------------------------------------------------
 	// Verify Endpoints
 	// ------------------------------------------------------------
-	if !strings.HasPrefix(s.Settings.Discovery, "/") {
-		s.Settings.Discovery = "/" + s.Settings.Discovery
-	}
-	if !strings.HasSuffix(s.Settings.Discovery, "/") {
-		s.Settings.Discovery = s.Settings.Discovery + "/"
-	}
-
-	if !strings.HasPrefix(s.Settings.APIRoot, "/") {
-		s.Settings.APIRoot = "/" + s.Settings.APIRoot
-	}
-	if !strings.HasSuffix(s.Settings.APIRoot, "/") {
-		s.Settings.APIRoot = s.Settings.APIRoot + "/"
-	}
+	s.Settings.Discovery = normalizePath(s.Settings.Discovery)
+	s.Settings.APIRoot = normalizePath(s.Settings.APIRoot)
 
 	// ------------------------------------------------------------
 	// Setup HTTP Client and Proxy if defined